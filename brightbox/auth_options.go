@@ -3,8 +3,10 @@ package brightbox
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/gophercloud/gophercloud"
@@ -18,27 +20,48 @@ import (
 var infrastructureScope = []string{"infrastructure, orbit"}
 
 type authdetails struct {
-	APIClient    string
-	APISecret    string
-	UserName     string
-	password     string
-	Account      string
-	APIURL       string
-	OrbitUrl     string
-	currentToken oauth2.TokenSource
+	APIClient            string
+	APISecret            string
+	UserName             string
+	password             string
+	otp                  string
+	Account              string
+	APIURL               string
+	OrbitUrl             string
+	MaxRequests          int
+	MaxRetries           int
+	UserDataLimit        int
+	DefaultMetadata      map[string]string
+	AllowedServerGroups  []string
+	NamePrefix           string
+	DefaultCreateTimeout time.Duration
+	DefaultDeleteTimeout time.Duration
+	DialTimeout          time.Duration
+	KeepAlive            time.Duration
+	HTTPTimeout          time.Duration
+	TokenCacheFile       string
+	currentToken         oauth2.TokenSource
 }
 
 // Authenticate the details and return a client
 func (authd *authdetails) authenticatedClient() (*brightbox.Client, *gophercloud.ServiceClient, error) {
-	authContext := contextWithLoggedHttpClient()
+	authContext := authd.contextWithLoggedHttpClient()
 	if authd.currentToken == nil {
+		cached := readCachedToken(authd.TokenCacheFile, authd.tokenCacheKey())
 		switch {
 		case authd.UserName != "" || authd.password != "":
-			if err := authd.getUserTokenSource(authContext); err != nil {
+			if err := authd.getUserTokenSource(authContext, cached); err != nil {
 				return nil, nil, err
 			}
 		default:
-			authd.getApiClientTokenSource(authContext)
+			authd.getApiClientTokenSource(authContext, cached)
+		}
+		if authd.TokenCacheFile != "" {
+			authd.currentToken = &cachingTokenSource{
+				source: authd.currentToken,
+				path:   authd.TokenCacheFile,
+				key:    authd.tokenCacheKey(),
+			}
 		}
 	}
 	log.Printf("[DEBUG] Fetching API Client")
@@ -73,7 +96,7 @@ func (authd *authdetails) storageURL() string {
 	return strings.TrimSuffix(authd.OrbitUrl, "/") + "/" + authd.Account + "/"
 }
 
-func (authd *authdetails) getUserTokenSource(ctx context.Context) error {
+func (authd *authdetails) getUserTokenSource(ctx context.Context, cached *oauth2.Token) error {
 	conf := oauth2.Config{
 		ClientID:     authd.APIClient,
 		ClientSecret: authd.APISecret,
@@ -84,8 +107,18 @@ func (authd *authdetails) getUserTokenSource(ctx context.Context) error {
 			AuthStyle: oauth2.AuthStyleInHeader,
 		},
 	}
+	if cached != nil {
+		log.Printf("[DEBUG] Using cached token for user %s", authd.UserName)
+		authd.currentToken = conf.TokenSource(ctx, cached)
+		return nil
+	}
 	log.Printf("[DEBUG] Obtaining Tokensource for user %s", authd.UserName)
-	token, err := conf.PasswordCredentialsToken(ctx, authd.UserName, authd.password)
+	password := authd.password
+	if authd.otp != "" {
+		log.Printf("[DEBUG] Appending One Time Password for user %s", authd.UserName)
+		password = password + authd.otp
+	}
+	token, err := conf.PasswordCredentialsToken(ctx, authd.UserName, password)
 	if err != nil {
 		return err
 	}
@@ -93,7 +126,7 @@ func (authd *authdetails) getUserTokenSource(ctx context.Context) error {
 	return nil
 }
 
-func (authd *authdetails) getApiClientTokenSource(ctx context.Context) {
+func (authd *authdetails) getApiClientTokenSource(ctx context.Context, cached *oauth2.Token) {
 	conf := clientcredentials.Config{
 		ClientID:     authd.APIClient,
 		ClientSecret: authd.APISecret,
@@ -102,15 +135,36 @@ func (authd *authdetails) getApiClientTokenSource(ctx context.Context) {
 		AuthStyle:    oauth2.AuthStyleInHeader,
 	}
 	log.Printf("[DEBUG] Obtaining Tokensource for client %s", authd.APIClient)
-	authd.currentToken = conf.TokenSource(ctx)
+	authd.currentToken = oauth2.ReuseTokenSource(cached, conf.TokenSource(ctx))
 }
 
-func contextWithLoggedHttpClient() context.Context {
-	client := cleanhttp.DefaultClient()
-	client.Transport = logging.NewTransport("Brightbox", client.Transport)
+func (authd *authdetails) contextWithLoggedHttpClient() context.Context {
+	client := &http.Client{
+		Transport: authd.httpTransport(),
+	}
 	return context.WithValue(context.Background(), oauth2.HTTPClient, client)
 }
 
+// The transport shared by the API and Orbit clients. http_timeout only
+// bounds the wait for response headers, so that large Orbit uploads and
+// downloads can take as long as they need once they are under way.
+func (authd *authdetails) httpTransport() http.RoundTripper {
+	transport := cleanhttp.DefaultTransport()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   authd.DialTimeout,
+		KeepAlive: authd.KeepAlive,
+		DualStack: true,
+	}).DialContext
+	transport.ResponseHeaderTimeout = authd.HTTPTimeout
+	return newRetryTransport(
+		newLimitedTransport(
+			logging.NewTransport("Brightbox", transport),
+			authd.MaxRequests,
+		),
+		authd.MaxRetries,
+	)
+}
+
 func (authd *authdetails) getServiceClient(ctx context.Context) (*gophercloud.ServiceClient, error) {
 	pc, err := authd.getProviderClient(ctx)
 	if err != nil {
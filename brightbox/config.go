@@ -2,14 +2,56 @@ package brightbox
 
 import (
 	"log"
+	"sync"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/gophercloud/gophercloud"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 )
 
 type CompositeClient struct {
 	ApiClient   *brightbox.Client
 	OrbitClient *gophercloud.ServiceClient
+	// Largest encoded user data accepted for a server
+	UserDataLimit int
+	// Metadata added to every Orbit container
+	DefaultMetadata map[string]string
+	// Server groups servers may be placed in, any if empty
+	AllowedServerGroups []string
+	// Prefix of the names given to resources created without one
+	NamePrefix string
+	// Timeouts replacing the resource defaults, if not zero
+	DefaultCreateTimeout time.Duration
+	DefaultDeleteTimeout time.Duration
+	// Locks serialising changes to the rules of each firewall policy
+	firewallPolicyLocks sync.Map
+}
+
+// Hold the lock on a firewall policy's rules, returning the function
+// that releases it.
+func (c *CompositeClient) lockFirewallPolicy(policy_id string) func() {
+	lock, _ := c.firewallPolicyLocks.LoadOrStore(policy_id, &sync.Mutex{})
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// The timeout for a create or delete. The provider's default timeouts
+// replace the package default, but not a timeout set in the resource's
+// own timeouts block.
+func (c *CompositeClient) timeout(d *schema.ResourceData, key string) time.Duration {
+	timeout := d.Timeout(key)
+	if timeout != defaultTimeout {
+		return timeout
+	}
+	switch {
+	case key == schema.TimeoutCreate && c.DefaultCreateTimeout > 0:
+		return c.DefaultCreateTimeout
+	case key == schema.TimeoutDelete && c.DefaultDeleteTimeout > 0:
+		return c.DefaultDeleteTimeout
+	}
+	return timeout
 }
 
 func (c *authdetails) Client() (*CompositeClient, error) {
@@ -20,11 +62,21 @@ func (c *authdetails) Client() (*CompositeClient, error) {
 
 	log.Printf("[INFO] Brightbox Client configured for URL: %s", apiclient.BaseURL.String())
 	log.Printf("[INFO] Provisioning to account %s", apiclient.AccountId)
+	if c.MaxRequests > 0 {
+		log.Printf("[INFO] Limiting API requests to %d at a time", c.MaxRequests)
+	}
+	log.Printf("[INFO] Retrying rate limited API requests up to %d times", c.MaxRetries)
 	log.Printf("[INFO] Orbit Client configured for URL: %s", orbitclient.ResourceBaseURL())
 
 	composite := &CompositeClient{
-		ApiClient:   apiclient,
-		OrbitClient: orbitclient,
+		ApiClient:            apiclient,
+		OrbitClient:          orbitclient,
+		UserDataLimit:        c.UserDataLimit,
+		DefaultMetadata:      c.DefaultMetadata,
+		AllowedServerGroups:  c.AllowedServerGroups,
+		NamePrefix:           c.NamePrefix,
+		DefaultCreateTimeout: c.DefaultCreateTimeout,
+		DefaultDeleteTimeout: c.DefaultDeleteTimeout,
 	}
 
 	return composite, nil
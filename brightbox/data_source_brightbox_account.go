@@ -0,0 +1,181 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+const (
+	accountPending = "pending"
+	accountActive  = "active"
+)
+
+func dataSourceBrightboxAccount() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxAccountRead,
+
+		Schema: map[string]*schema.Schema{
+			"wait_for_active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			//Computed Values
+			"name": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"default_server_group": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"valid_credit_card": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"telephone_verified": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"ram_limit": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"ram_used": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"dbs_ram_limit": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"dbs_ram_used": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"cloud_ips_limit": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"cloud_ips_used": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"load_balancers_limit": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"load_balancers_used": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxAccountRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	log.Printf("[DEBUG] Account data read called for %s", client.AccountId)
+	var account *brightbox.Account
+	var err error
+	if d.Get("wait_for_active").(bool) {
+		account, err = waitForAccountStatus(client, client.AccountId, accountActive, defaultTimeout)
+	} else {
+		account, err = client.Account(client.AccountId)
+	}
+	if err != nil {
+		return fmt.Errorf("Error retrieving account details: %s", err)
+	}
+
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return fmt.Errorf("Error retrieving server group list: %s", err)
+	}
+
+	d.SetId(account.Id)
+	d.Set("name", account.Name)
+	d.Set("status", account.Status)
+	d.Set("default_server_group", defaultServerGroupId(groups))
+	d.Set("valid_credit_card", account.ValidCreditCard)
+	d.Set("telephone_verified", account.TelephoneVerified)
+	d.Set("ram_limit", account.RamLimit)
+	d.Set("ram_used", account.RamUsed)
+	d.Set("dbs_ram_limit", account.DbsRamLimit)
+	d.Set("dbs_ram_used", account.DbsRamUsed)
+	d.Set("cloud_ips_limit", account.CloudIpsLimit)
+	d.Set("cloud_ips_used", account.CloudIpsUsed)
+	d.Set("load_balancers_limit", account.LoadBalancersLimit)
+	d.Set("load_balancers_used", account.LoadBalancersUsed)
+	return nil
+}
+
+// The API marks exactly one server group per account as the default.
+// New servers are placed in it when no server groups are given.
+func defaultServerGroupId(groups []brightbox.ServerGroup) string {
+	for _, group := range groups {
+		if group.Default {
+			return group.Id
+		}
+	}
+	return ""
+}
+
+// Account changes such as activation happen asynchronously. Wait while
+// the account is pending; any other status fails straight away.
+func waitForAccountStatus(
+	client *brightbox.Client,
+	account_id string,
+	target string,
+	timeout time.Duration,
+) (*brightbox.Account, error) {
+	log.Printf("[INFO] Waiting for Account %s to become %s", account_id, target)
+	stateConf := resource.StateChangeConf{
+		Pending:    []string{accountPending},
+		Target:     []string{target},
+		Refresh:    accountStateRefresh(client, account_id),
+		Timeout:    timeout,
+		MinTimeout: minimumRefreshWait,
+	}
+	account, err := stateConf.WaitForState()
+	if err != nil {
+		return nil, err
+	}
+	return account.(*brightbox.Account), nil
+}
+
+func accountStateRefresh(client *brightbox.Client, account_id string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		account, err := client.Account(account_id)
+		if err != nil {
+			log.Printf("Error on Account State Refresh: %s", err)
+			return nil, "", err
+		}
+		return account, account.Status, nil
+	}
+}
@@ -0,0 +1,87 @@
+package brightbox
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataAccount_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBrightboxDataAccountConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.brightbox_account.current", "id", regexp.MustCompile("^acc-.....$")),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_account.current", "status", "active"),
+					resource.TestCheckResourceAttrSet(
+						"data.brightbox_account.current", "ram_limit"),
+					resource.TestCheckResourceAttrSet(
+						"data.brightbox_account.current", "cloud_ips_limit"),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_account.current", "default_server_group",
+						"data.brightbox_server_group.default", "id"),
+				),
+			},
+		},
+	})
+}
+
+func TestDefaultServerGroupId(t *testing.T) {
+	groups := []brightbox.ServerGroup{
+		{Id: "grp-aaaaa"},
+		{Id: "grp-bbbbb", Default: true},
+	}
+	if result := defaultServerGroupId(groups); result != "grp-bbbbb" {
+		t.Errorf("Expected grp-bbbbb, got %q", result)
+	}
+	if result := defaultServerGroupId(groups[:1]); result != "" {
+		t.Errorf("Expected no default group, got %q", result)
+	}
+}
+
+func TestWaitForAccountStatus(t *testing.T) {
+	status := "active"
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.0/accounts/acc-12345" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"acc-12345","status":%q}`, status)
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	account, err := waitForAccountStatus(client, "acc-12345", accountActive, time.Minute)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if account.Id != "acc-12345" {
+		t.Errorf("Got account %q, expected acc-12345", account.Id)
+	}
+
+	status = "suspended"
+	if _, err := waitForAccountStatus(client, "acc-12345", accountActive, time.Minute); err == nil {
+		t.Errorf("Expected an error for a suspended account")
+	}
+}
+
+const TestAccBrightboxDataAccountConfig_basic = `
+data "brightbox_account" "current" {
+	wait_for_active = true
+}
+
+data "brightbox_server_group" "default" {
+	default = true
+}
+`
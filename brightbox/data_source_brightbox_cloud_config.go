@@ -0,0 +1,134 @@
+package brightbox
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	yaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const cloudConfigHeader = "#cloud-config\n"
+
+func dataSourceBrightboxCloudConfig() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxCloudConfigRead,
+
+		Schema: map[string]*schema.Schema{
+			"base": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			"override": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			//Computed Values
+			"rendered": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxCloudConfigRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	log.Printf("[DEBUG] Merging cloud-config documents")
+	rendered, err := mergeCloudConfigDocuments(
+		d.Get("base").(string),
+		d.Get("override").(string),
+	)
+	if err != nil {
+		return err
+	}
+	sum := sha1.Sum([]byte(rendered))
+	d.SetId(hex.EncodeToString(sum[:]))
+	d.Set("rendered", rendered)
+	return nil
+}
+
+// Deep merge the override cloud-config into the base. Mappings are
+// merged key by key, lists such as write_files and runcmd are
+// concatenated, and any other override value replaces the base value.
+// Keys are emitted in sorted order so the result hashes consistently.
+func mergeCloudConfigDocuments(base string, override string) (string, error) {
+	base_value, err := parseCloudConfig("base", base)
+	if err != nil {
+		return "", err
+	}
+	override_value, err := parseCloudConfig("override", override)
+	if err != nil {
+		return "", err
+	}
+	result, err := yaml.Marshal(mergeCloudConfigValues(base_value, override_value))
+	if err != nil {
+		return "", fmt.Errorf("Error rendering cloud-config: %s", err)
+	}
+	result = bytes.TrimPrefix(result, []byte("---\n"))
+	return cloudConfigHeader + string(result), nil
+}
+
+func parseCloudConfig(name string, document string) (cty.Value, error) {
+	if strings.TrimSpace(document) == "" {
+		return cty.EmptyObjectVal, nil
+	}
+	value, err := yaml.Unmarshal([]byte(document), cty.DynamicPseudoType)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("Error parsing %s cloud-config: %s", name, err)
+	}
+	if value.IsNull() {
+		return cty.EmptyObjectVal, nil
+	}
+	if !isCloudConfigMapping(value) {
+		return cty.NilVal, fmt.Errorf("The %s cloud-config must be a YAML mapping", name)
+	}
+	return value, nil
+}
+
+func mergeCloudConfigValues(base cty.Value, override cty.Value) cty.Value {
+	switch {
+	case base.IsNull() || override.IsNull():
+		return override
+	case isCloudConfigMapping(base) && isCloudConfigMapping(override):
+		attrs := base.AsValueMap()
+		if attrs == nil {
+			attrs = map[string]cty.Value{}
+		}
+		for key, value := range override.AsValueMap() {
+			if existing, ok := attrs[key]; ok {
+				attrs[key] = mergeCloudConfigValues(existing, value)
+			} else {
+				attrs[key] = value
+			}
+		}
+		return cty.ObjectVal(attrs)
+	case isCloudConfigList(base) && isCloudConfigList(override):
+		elems := append(base.AsValueSlice(), override.AsValueSlice()...)
+		if len(elems) == 0 {
+			return cty.EmptyTupleVal
+		}
+		return cty.TupleVal(elems)
+	default:
+		return override
+	}
+}
+
+func isCloudConfigMapping(value cty.Value) bool {
+	ty := value.Type()
+	return ty.IsObjectType() || ty.IsMapType()
+}
+
+func isCloudConfigList(value cty.Value) bool {
+	ty := value.Type()
+	return ty.IsTupleType() || ty.IsListType()
+}
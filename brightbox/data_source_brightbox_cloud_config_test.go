@@ -0,0 +1,110 @@
+package brightbox
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+const testCloudConfigBase = `#cloud-config
+package_update: true
+packages:
+  - nginx
+write_files:
+  - path: /etc/motd
+    content: base
+runcmd:
+  - systemctl enable nginx
+users:
+  default:
+    shell: /bin/bash
+`
+
+const testCloudConfigOverride = `
+package_update: false
+write_files:
+  - path: /etc/app.conf
+    content: web
+runcmd:
+  - [ systemctl, start, nginx ]
+users:
+  default:
+    groups: www-data
+`
+
+const testCloudConfigMerged = `#cloud-config
+"package_update": false
+"packages":
+- "nginx"
+"runcmd":
+- "systemctl enable nginx"
+- - "systemctl"
+  - "start"
+  - "nginx"
+"users":
+  "default":
+    "groups": "www-data"
+    "shell": "/bin/bash"
+"write_files":
+- "content": "base"
+  "path": "/etc/motd"
+- "content": "web"
+  "path": "/etc/app.conf"
+`
+
+func TestMergeCloudConfigDocuments(t *testing.T) {
+	rendered, err := mergeCloudConfigDocuments(testCloudConfigBase, testCloudConfigOverride)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if rendered != testCloudConfigMerged {
+		t.Errorf("Got merged cloud-config:\n%s", rendered)
+	}
+	again, _ := mergeCloudConfigDocuments(testCloudConfigBase, testCloudConfigOverride)
+	if again != rendered {
+		t.Errorf("Expected merging to be repeatable")
+	}
+}
+
+func TestMergeCloudConfigDocuments_noOverride(t *testing.T) {
+	rendered, err := mergeCloudConfigDocuments("#cloud-config\nruncmd:\n  - reboot\n", "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if expected := "#cloud-config\n\"runcmd\":\n- \"reboot\"\n"; rendered != expected {
+		t.Errorf("Got %q, expected %q", rendered, expected)
+	}
+}
+
+func TestMergeCloudConfigDocuments_invalid(t *testing.T) {
+	if _, err := mergeCloudConfigDocuments("- runcmd\n", ""); err == nil {
+		t.Errorf("Expected an error for a base that is not a mapping")
+	}
+	if _, err := mergeCloudConfigDocuments("runcmd: []\n", "runcmd: [unclosed\n"); err == nil {
+		t.Errorf("Expected an error for an override that is not valid YAML")
+	}
+}
+
+func TestAccBrightboxDataCloudConfig_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBrightboxDataCloudConfigConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.brightbox_cloud_config.web", "rendered",
+						"#cloud-config\n\"runcmd\":\n- \"apt-get update\"\n- \"systemctl start nginx\"\n"),
+				),
+			},
+		},
+	})
+}
+
+const testAccBrightboxDataCloudConfigConfig_basic = `
+data "brightbox_cloud_config" "web" {
+	base = "runcmd: [ 'apt-get update' ]"
+	override = "runcmd: [ 'systemctl start nginx' ]"
+}
+`
@@ -0,0 +1,109 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+var cloudipIdRe = regexp.MustCompile("^cip-[0-9a-z]{5}$")
+
+func dataSourceBrightboxCloudip() *schema.Resource {
+	cloudipSchema := computedSchema(resourceBrightboxCloudip().Schema)
+	cloudipSchema["id"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ValidateFunc:  validation.StringMatch(cloudipIdRe, "must be a valid Cloud IP ID"),
+		ConflictsWith: []string{"name"},
+	}
+	cloudipSchema["name"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{"id"},
+	}
+	for _, key := range []string{"server", "interface", "load_balancer"} {
+		cloudipSchema[key] = &schema.Schema{
+			Type:     schema.TypeString,
+			Computed: true,
+		}
+	}
+	return &schema.Resource{
+		Read:   dataSourceBrightboxCloudipRead,
+		Schema: cloudipSchema,
+	}
+}
+
+func dataSourceBrightboxCloudipRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	var cloudip *brightbox.CloudIP
+	if id, ok := d.GetOk("id"); ok {
+		log.Printf("[DEBUG] Cloud IP data read called for %s", id)
+		found, err := client.CloudIP(id.(string))
+		if err != nil {
+			return fmt.Errorf("Error retrieving Cloud IP details: %s", err)
+		}
+		cloudip = found
+	} else if name, ok := d.GetOk("name"); ok {
+		log.Printf("[DEBUG] Cloud IP data read called. Retrieving Cloud IP list")
+		cloudips, err := client.CloudIPs()
+		if err != nil {
+			return fmt.Errorf("Error retrieving Cloud IP list: %s", err)
+		}
+		cloudip, err = findCloudipByName(cloudips, name.(string))
+		if err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("One of id or name must be given to look up a Cloud IP")
+	}
+
+	log.Printf("[DEBUG] Single Cloud IP found: %s", cloudip.Id)
+	d.SetId(cloudip.Id)
+	d.Set("id", cloudip.Id)
+	d.Set("server", "")
+	if cloudip.Server != nil {
+		d.Set("server", cloudip.Server.Id)
+	}
+	d.Set("interface", "")
+	if cloudip.Interface != nil {
+		d.Set("interface", cloudip.Interface.Id)
+	}
+	d.Set("load_balancer", "")
+	if cloudip.LoadBalancer != nil {
+		d.Set("load_balancer", cloudip.LoadBalancer.Id)
+	}
+	return setCloudipAttributes(d, cloudip)
+}
+
+// Find the one Cloud IP with the given name. Names are compared
+// without any managed-by tag, so IPs created by Terraform match too.
+func findCloudipByName(
+	cloudips []brightbox.CloudIP,
+	name string,
+) (*brightbox.CloudIP, error) {
+	var results []brightbox.CloudIP
+	for _, cloudip := range cloudips {
+		plain_name, _ := splitManagedName(cloudip.Name)
+		if cloudip.Name == name || plain_name == name {
+			results = append(results, cloudip)
+		}
+	}
+	if len(results) == 1 {
+		return &results[0], nil
+	} else if len(results) > 1 {
+		return nil, fmt.Errorf("Your query returned more than one result (found %d Cloud IPs named %q). "+
+			"Please look the Cloud IP up by id instead.", len(results), name)
+	} else {
+		return nil, fmt.Errorf("Your query returned no results. No Cloud IP is named %q.", name)
+	}
+}
@@ -0,0 +1,115 @@
+package brightbox
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataCloudip_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxCloudipDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDataCloudipConfig_basic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_cloudip.by_name", "id",
+						"brightbox_cloudip.foobar", "id"),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_cloudip.by_id", "public_ip",
+						"brightbox_cloudip.foobar", "public_ip"),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_cloudip.by_id", "fqdn",
+						"brightbox_cloudip.foobar", "fqdn"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_cloudip.by_id", "status", "unmapped"),
+				),
+			},
+		},
+	})
+}
+
+func TestFindCloudipByName(t *testing.T) {
+	cloudips := []brightbox.CloudIP{
+		{Id: "cip-aaaaa", Name: "mail"},
+		{Id: "cip-bbbbb", Name: "web [managed-by:terraform]"},
+		{Id: "cip-ccccc", Name: "vpn"},
+		{Id: "cip-ddddd", Name: "vpn"},
+	}
+	for name, expected := range map[string]string{"mail": "cip-aaaaa", "web": "cip-bbbbb"} {
+		cloudip, err := findCloudipByName(cloudips, name)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if cloudip.Id != expected {
+			t.Errorf("%s: got Cloud IP %q, expected %q", name, cloudip.Id, expected)
+		}
+	}
+	if _, err := findCloudipByName(cloudips, "vpn"); err == nil {
+		t.Errorf("Expected an error for a name shared by two Cloud IPs")
+	}
+	if _, err := findCloudipByName(cloudips, "db"); err == nil {
+		t.Errorf("Expected an error when no Cloud IP matches")
+	}
+}
+
+func TestDataSourceBrightboxCloudipRead(t *testing.T) {
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.0/cloud_ips/cip-12345" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"cip-12345","name":"mail","status":"mapped",
+			"public_ip":"109.107.1.1","public_ipv6":"2a02:1348::1",
+			"reverse_dns":"mail.example.com","fqdn":"cip-12345.gb1.brightbox.com",
+			"server":{"id":"srv-12345"},"interface":{"id":"int-12345"}}`))
+	})
+	defer server.Close()
+
+	d := dataSourceBrightboxCloudip().Data(nil)
+	d.Set("id", "cip-12345")
+	if err := dataSourceBrightboxCloudipRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]string{
+		"name":          "mail",
+		"public_ip":     "109.107.1.1",
+		"public_ipv6":   "2a02:1348::1",
+		"reverse_dns":   "mail.example.com",
+		"status":        "mapped",
+		"server":        "srv-12345",
+		"interface":     "int-12345",
+		"load_balancer": "",
+		"target":        "int-12345",
+	}
+	for key, value := range expected {
+		if got := d.Get(key).(string); got != value {
+			t.Errorf("Got %s %q, expected %q", key, got, value)
+		}
+	}
+}
+
+func testAccCheckBrightboxDataCloudipConfig_basic(rInt int) string {
+	return fmt.Sprintf(`
+resource "brightbox_cloudip" "foobar" {
+	name = "bar-%d"
+}
+
+data "brightbox_cloudip" "by_name" {
+	name = "${brightbox_cloudip.foobar.name}"
+}
+
+data "brightbox_cloudip" "by_id" {
+	id = "${brightbox_cloudip.foobar.id}"
+}
+`, rInt)
+}
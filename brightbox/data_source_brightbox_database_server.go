@@ -0,0 +1,131 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+var databaseServerIdRe = regexp.MustCompile("^dbs-[0-9a-z]{5}$")
+
+func dataSourceBrightboxDatabaseServer() *schema.Resource {
+	databaseServerSchema := computedSchema(resourceBrightboxDatabaseServer().Schema)
+	// Settings only used when creating or changing a database server
+	delete(databaseServerSchema, "admin_password")
+	delete(databaseServerSchema, "reset_admin_password")
+	delete(databaseServerSchema, "snapshot")
+	databaseServerSchema["id"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ValidateFunc:  validation.StringMatch(databaseServerIdRe, "must be a valid database server ID"),
+		ConflictsWith: []string{"name"},
+	}
+	databaseServerSchema["name"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{"id"},
+	}
+	databaseServerSchema["server_groups"] = &schema.Schema{
+		Type:     schema.TypeSet,
+		Computed: true,
+		Elem:     &schema.Schema{Type: schema.TypeString},
+		Set:      schema.HashString,
+	}
+	databaseServerSchema["ipv4_address"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Computed: true,
+	}
+	return &schema.Resource{
+		Read:   dataSourceBrightboxDatabaseServerRead,
+		Schema: databaseServerSchema,
+	}
+}
+
+func dataSourceBrightboxDatabaseServerRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	var database_server *brightbox.DatabaseServer
+	if id, ok := d.GetOk("id"); ok {
+		log.Printf("[DEBUG] Database Server data read called for %s", id)
+		found, err := client.DatabaseServer(id.(string))
+		if err != nil {
+			return fmt.Errorf("Error retrieving Database Server details: %s", err)
+		}
+		if found.Status == "deleted" {
+			return fmt.Errorf("Database Server %s has been deleted", id)
+		}
+		database_server = found
+	} else if name, ok := d.GetOk("name"); ok {
+		log.Printf("[DEBUG] Database Server data read called. Retrieving database server list")
+		database_servers, err := client.DatabaseServers()
+		if err != nil {
+			return fmt.Errorf("Error retrieving Database Server list: %s", err)
+		}
+		found, err := findDatabaseServerByName(database_servers, name.(string))
+		if err != nil {
+			return err
+		}
+		database_server, err = client.DatabaseServer(found.Id)
+		if err != nil {
+			return fmt.Errorf("Error retrieving Database Server details: %s", err)
+		}
+	} else {
+		return fmt.Errorf("One of id or name must be given to look up a database server")
+	}
+
+	log.Printf("[DEBUG] Single Database Server found: %s", database_server.Id)
+	d.SetId(database_server.Id)
+	d.Set("id", database_server.Id)
+	setDatabaseServerAttributes(d, database_server)
+	setAllowAccessAttribute(d, database_server)
+	d.Set("server_groups", databaseServerGroups(database_server.AllowAccess))
+	ipv4_address := ""
+	if len(database_server.CloudIPs) > 0 {
+		ipv4_address = database_server.CloudIPs[0].PublicIP
+	}
+	d.Set("ipv4_address", ipv4_address)
+	return nil
+}
+
+// Find the one live database server with exactly the given name.
+func findDatabaseServerByName(
+	database_servers []brightbox.DatabaseServer,
+	name string,
+) (*brightbox.DatabaseServer, error) {
+	var results []brightbox.DatabaseServer
+	for _, database_server := range database_servers {
+		if database_server.Name == name && database_server.Status != "deleted" {
+			results = append(results, database_server)
+		}
+	}
+	if len(results) == 1 {
+		return &results[0], nil
+	} else if len(results) > 1 {
+		return nil, fmt.Errorf("Your query returned more than one result (found %d database servers named %q). "+
+			"Please look the database server up by id instead.", len(results), name)
+	} else {
+		return nil, fmt.Errorf("Your query returned no results. No database server is named %q.", name)
+	}
+}
+
+// The server groups among the sources allowed to reach a database
+// server. The rest are servers, load balancers and addresses.
+func databaseServerGroups(allow_access []string) []interface{} {
+	groups := []interface{}{}
+	for _, source := range allow_access {
+		if strings.HasPrefix(source, "grp-") {
+			groups = append(groups, source)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,127 @@
+package brightbox
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataDatabaseServer_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	name := fmt.Sprintf("foo-%d", rInt)
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxDatabaseServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDataDatabaseServerConfig_basic(name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_database_server.by_name", "id",
+						"brightbox_database_server.foobar", "id"),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_database_server.by_id", "admin_username",
+						"brightbox_database_server.foobar", "admin_username"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_database_server.by_id", "server_groups.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestFindDatabaseServerByName(t *testing.T) {
+	database_servers := []brightbox.DatabaseServer{
+		{Id: "dbs-aaaaa", Name: "app", Status: "active"},
+		{Id: "dbs-bbbbb", Name: "app", Status: "deleted"},
+		{Id: "dbs-ccccc", Name: "reports", Status: "active"},
+		{Id: "dbs-ddddd", Name: "reports", Status: "creating"},
+	}
+	database_server, err := findDatabaseServerByName(database_servers, "app")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if database_server.Id != "dbs-aaaaa" {
+		t.Errorf("Got database server %q, expected dbs-aaaaa", database_server.Id)
+	}
+	if _, err := findDatabaseServerByName(database_servers, "reports"); err == nil {
+		t.Errorf("Expected an error for a name shared by two database servers")
+	}
+	if _, err := findDatabaseServerByName(database_servers, "ap"); err == nil {
+		t.Errorf("Expected an error when no database server matches")
+	}
+}
+
+func TestDataSourceBrightboxDatabaseServerRead(t *testing.T) {
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1.0/database_servers":
+			w.Write([]byte(`[{"id":"dbs-12345","name":"app","status":"active"}]`))
+		case "/1.0/database_servers/dbs-12345":
+			w.Write([]byte(`{"id":"dbs-12345","name":"app","status":"active",
+				"database_engine":"mysql","database_version":"5.7","admin_username":"admin",
+				"allow_access":["grp-12345","srv-12345","10.0.0.0/8"],
+				"cloud_ips":[{"id":"cip-12345","public_ip":"109.107.1.1"}],
+				"database_server_type":{"id":"dbt-12345"},
+				"zone":{"handle":"gb1-a"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer server.Close()
+
+	d := dataSourceBrightboxDatabaseServer().Data(nil)
+	d.Set("name", "app")
+	if err := dataSourceBrightboxDatabaseServerRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "dbs-12345" {
+		t.Errorf("Got id %q, expected dbs-12345", d.Id())
+	}
+	expected := map[string]string{
+		"admin_username":   "admin",
+		"database_engine":  "mysql",
+		"database_version": "5.7",
+		"status":           "active",
+		"ipv4_address":     "109.107.1.1",
+		"zone":             "gb1-a",
+	}
+	for key, value := range expected {
+		if got := d.Get(key).(string); got != value {
+			t.Errorf("Got %s %q, expected %q", key, got, value)
+		}
+	}
+	if got := d.Get("server_groups.#").(int); got != 1 {
+		t.Errorf("Expected one server group, got %d", got)
+	}
+	if got := d.Get("allow_access.#").(int); got != 3 {
+		t.Errorf("Expected three access sources, got %d", got)
+	}
+}
+
+func testAccCheckBrightboxDataDatabaseServerConfig_basic(name string) string {
+	return fmt.Sprintf(`
+resource "brightbox_server_group" "barfoo" {
+	name = "%s"
+}
+
+resource "brightbox_database_server" "foobar" {
+	name = "%s"
+	allow_access = ["${brightbox_server_group.barfoo.id}"]
+}
+
+data "brightbox_database_server" "by_name" {
+	name = "${brightbox_database_server.foobar.name}"
+}
+
+data "brightbox_database_server" "by_id" {
+	id = "${brightbox_database_server.foobar.id}"
+}
+`, name, name)
+}
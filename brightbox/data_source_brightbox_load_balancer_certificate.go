@@ -0,0 +1,99 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceBrightboxLoadBalancerCertificate() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxLoadBalancerCertificateRead,
+
+		Schema: map[string]*schema.Schema{
+			"load_balancer": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			//Computed Values
+			"subject": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"issuer": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"valid_from": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"expires_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"fingerprint": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxLoadBalancerCertificateRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	load_balancer_id := d.Get("load_balancer").(string)
+	log.Printf("[DEBUG] Load Balancer certificate data read called for %s", load_balancer_id)
+	load_balancer, err := client.LoadBalancer(load_balancer_id)
+	if err != nil {
+		return fmt.Errorf("Error retrieving Load Balancer details: %s", err)
+	}
+
+	d.SetId(load_balancer.Id)
+	return setLoadBalancerCertificateAttributes(d, load_balancer)
+}
+
+// The installed certificate is either one uploaded with the load
+// balancer or one issued through ACME. An uploaded certificate is used
+// in preference, and every attribute comes from the certificate used.
+// Only ACME certificates have a fingerprint in the API, and only
+// uploaded ones a subject and issuer.
+func setLoadBalancerCertificateAttributes(
+	d *schema.ResourceData,
+	load_balancer *brightbox.LoadBalancer,
+) error {
+	var acme_certificate *brightbox.LoadBalancerAcmeCertificate
+	if load_balancer.Acme != nil {
+		acme_certificate = load_balancer.Acme.Certificate
+	}
+	certificate := load_balancer.Certificate
+	if certificate == nil && acme_certificate == nil {
+		return fmt.Errorf("Load Balancer %s has no certificate installed", load_balancer.Id)
+	}
+	if certificate != nil {
+		d.Set("subject", certificate.Subject)
+		d.Set("issuer", certificate.Issuer)
+		d.Set("valid_from", certificate.ValidFrom.Format(time.RFC3339))
+		d.Set("expires_at", certificate.ExpiresAt.Format(time.RFC3339))
+		d.Set("fingerprint", "")
+	} else {
+		d.Set("subject", "")
+		d.Set("issuer", "")
+		d.Set("valid_from", acme_certificate.IssuedAt.Format(time.RFC3339))
+		d.Set("expires_at", acme_certificate.ExpiresAt.Format(time.RFC3339))
+		d.Set("fingerprint", acme_certificate.Fingerprint)
+	}
+	return nil
+}
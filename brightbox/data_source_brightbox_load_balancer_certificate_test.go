@@ -0,0 +1,104 @@
+package brightbox
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataLoadBalancerCertificate_basic(t *testing.T) {
+	var load_balancer brightbox.LoadBalancer
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxLoadBalancerAndServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDataLoadBalancerCertificateConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxLoadBalancerExists("brightbox_load_balancer.default", &load_balancer),
+					resource.TestCheckResourceAttrSet(
+						"data.brightbox_load_balancer_certificate.default", "subject"),
+					resource.TestCheckResourceAttrSet(
+						"data.brightbox_load_balancer_certificate.default", "issuer"),
+					resource.TestMatchResourceAttr(
+						"data.brightbox_load_balancer_certificate.default", "expires_at",
+						regexp.MustCompile(`^\d{4}-\d\d-\d\dT`)),
+				),
+			},
+		},
+	})
+}
+
+func TestSetLoadBalancerCertificateAttributes(t *testing.T) {
+	expires := time.Date(2020, 1, 31, 12, 0, 0, 0, time.UTC)
+	d := dataSourceBrightboxLoadBalancerCertificate().Data(nil)
+	load_balancer := &brightbox.LoadBalancer{
+		Id: "lba-12345",
+		Certificate: &brightbox.LoadBalancerCertificate{
+			Subject:   "/CN=www.example.com",
+			Issuer:    "/CN=Example CA",
+			ExpiresAt: expires,
+		},
+	}
+	if err := setLoadBalancerCertificateAttributes(d, load_balancer); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("subject").(string); got != "/CN=www.example.com" {
+		t.Errorf("Got subject %q", got)
+	}
+	if got := d.Get("expires_at").(string); got != "2020-01-31T12:00:00Z" {
+		t.Errorf("Got expires_at %q", got)
+	}
+
+	d = dataSourceBrightboxLoadBalancerCertificate().Data(nil)
+	load_balancer = &brightbox.LoadBalancer{
+		Id: "lba-12345",
+		Acme: &brightbox.LoadBalancerAcme{
+			Certificate: &brightbox.LoadBalancerAcmeCertificate{
+				Fingerprint: "ab:cd:ef",
+				ExpiresAt:   expires,
+			},
+		},
+	}
+	if err := setLoadBalancerCertificateAttributes(d, load_balancer); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("fingerprint").(string); got != "ab:cd:ef" {
+		t.Errorf("Got fingerprint %q", got)
+	}
+	if got := d.Get("expires_at").(string); got != "2020-01-31T12:00:00Z" {
+		t.Errorf("Got expires_at %q from the ACME certificate", got)
+	}
+
+	// An uploaded certificate takes precedence over the ACME one, and
+	// has no fingerprint of its own
+	load_balancer.Certificate = &brightbox.LoadBalancerCertificate{
+		Subject:   "/CN=www.example.com",
+		ExpiresAt: expires.AddDate(1, 0, 0),
+	}
+	if err := setLoadBalancerCertificateAttributes(d, load_balancer); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("fingerprint").(string); got != "" {
+		t.Errorf("Got fingerprint %q from the ACME certificate for an uploaded one", got)
+	}
+	if got := d.Get("expires_at").(string); got != "2021-01-31T12:00:00Z" {
+		t.Errorf("Got expires_at %q, expected the uploaded certificate's", got)
+	}
+
+	if err := setLoadBalancerCertificateAttributes(d, &brightbox.LoadBalancer{Id: "lba-12345"}); err == nil {
+		t.Errorf("Expected an error for a load balancer with no certificate")
+	}
+}
+
+var testAccCheckBrightboxDataLoadBalancerCertificateConfig_basic = testAccCheckBrightboxLoadBalancerConfig_add_listener + `
+
+data "brightbox_load_balancer_certificate" "default" {
+	load_balancer = "${brightbox_load_balancer.default.id}"
+}
+`
@@ -0,0 +1,117 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func dataSourceBrightboxServer() *schema.Resource {
+	serverSchema := computedSchema(resourceBrightboxServer().Schema)
+	serverSchema["id"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ValidateFunc:  validation.StringMatch(serverIdRe, "must be a valid server ID"),
+		ConflictsWith: []string{"name"},
+	}
+	serverSchema["name"] = &schema.Schema{
+		Type:          schema.TypeString,
+		Optional:      true,
+		Computed:      true,
+		ConflictsWith: []string{"id"},
+	}
+	return &schema.Resource{
+		Read:   dataSourceBrightboxServerRead,
+		Schema: serverSchema,
+	}
+}
+
+// Convert a resource schema into one where every attribute is only
+// computed, so a data source can share the resource's attribute setters.
+func computedSchema(source map[string]*schema.Schema) map[string]*schema.Schema {
+	result := make(map[string]*schema.Schema, len(source))
+	for key, field := range source {
+		computed := &schema.Schema{
+			Type:        field.Type,
+			Computed:    true,
+			Sensitive:   field.Sensitive,
+			Set:         field.Set,
+			Description: field.Description,
+		}
+		switch elem := field.Elem.(type) {
+		case *schema.Resource:
+			computed.Elem = &schema.Resource{Schema: computedSchema(elem.Schema)}
+		default:
+			computed.Elem = elem
+		}
+		result[key] = computed
+	}
+	return result
+}
+
+func dataSourceBrightboxServerRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	var server *brightbox.Server
+	if id, ok := d.GetOk("id"); ok {
+		log.Printf("[DEBUG] Server data read called for %s", id)
+		found, err := client.Server(id.(string))
+		if err != nil {
+			return fmt.Errorf("Error retrieving server details: %s", err)
+		}
+		if found.Status == "deleted" {
+			return fmt.Errorf("Server %s has been deleted", id)
+		}
+		server = found
+	} else if name, ok := d.GetOk("name"); ok {
+		log.Printf("[DEBUG] Server data read called. Retrieving server list")
+		servers, err := client.Servers()
+		if err != nil {
+			return fmt.Errorf("Error retrieving server list: %s", err)
+		}
+		found, err := findServerByName(servers, name.(string))
+		if err != nil {
+			return err
+		}
+		// The list omits details such as the user data and groups
+		server, err = client.Server(found.Id)
+		if err != nil {
+			return fmt.Errorf("Error retrieving server details: %s", err)
+		}
+	} else {
+		return fmt.Errorf("One of id or name must be given to look up a server")
+	}
+
+	log.Printf("[DEBUG] Single Server found: %s", server.Id)
+	d.SetId(server.Id)
+	d.Set("id", server.Id)
+	return setServerAttributes(d, server)
+}
+
+// Find the one live server with exactly the given name.
+func findServerByName(
+	servers []brightbox.Server,
+	name string,
+) (*brightbox.Server, error) {
+	var results []brightbox.Server
+	for _, server := range servers {
+		if server.Name == name && server.Status != "deleted" {
+			results = append(results, server)
+		}
+	}
+	if len(results) == 1 {
+		return &results[0], nil
+	} else if len(results) > 1 {
+		return nil, fmt.Errorf("Your query returned more than one result (found %d servers named %q). "+
+			"Please look the server up by id instead.", len(results), name)
+	} else {
+		return nil, fmt.Errorf("Your query returned no results. No server is named %q.", name)
+	}
+}
@@ -0,0 +1,62 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceBrightboxServerConsole() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxServerConsoleRead,
+
+		Schema: map[string]*schema.Schema{
+			"server": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			//Computed Values
+			"url": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"token": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
+			"token_expires": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxServerConsoleRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	server_id := d.Get("server").(string)
+	log.Printf("[INFO] Activating console for server %s", server_id)
+	server, err := client.ActivateConsoleForServer(server_id)
+	if err != nil {
+		return fmt.Errorf("Error activating console for server %s: %s", server_id, err)
+	}
+
+	d.SetId(server.Id)
+	d.Set("url", server.FullConsoleUrl())
+	d.Set("token", server.ConsoleToken)
+	if server.ConsoleTokenExpires != nil {
+		d.Set("token_expires", server.ConsoleTokenExpires.Format(time.RFC3339))
+	}
+	return nil
+}
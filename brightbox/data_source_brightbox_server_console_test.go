@@ -0,0 +1,46 @@
+package brightbox
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataServerConsole_basic(t *testing.T) {
+	var server brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDataServerConsoleConfig_basic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &server),
+					resource.TestMatchResourceAttr(
+						"data.brightbox_server_console.foobar", "url", regexp.MustCompile("^https://")),
+					resource.TestCheckResourceAttrSet(
+						"data.brightbox_server_console.foobar", "token"),
+					resource.TestCheckResourceAttrSet(
+						"data.brightbox_server_console.foobar", "token_expires"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxDataServerConsoleConfig_basic(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+data "brightbox_server_console" "foobar" {
+	server = "${brightbox_server.foobar.id}"
+}
+`, testAccCheckBrightboxServerConfig_basic(rInt))
+}
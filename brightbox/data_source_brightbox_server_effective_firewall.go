@@ -0,0 +1,167 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceBrightboxServerEffectiveFirewall() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxServerEffectiveFirewallRead,
+
+		Schema: map[string]*schema.Schema{
+			"server": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			//Computed Values
+			"server_groups": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+
+			"firewall_policies": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+				Set:      schema.HashString,
+			},
+
+			"rule": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"firewall_policy": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"server_group": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"protocol": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"source_port": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"destination": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"destination_port": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"icmp_type_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"description": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxServerEffectiveFirewallRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	server_id := d.Get("server").(string)
+	log.Printf("[DEBUG] Effective firewall data read called for server %s", server_id)
+	server, err := client.Server(server_id)
+	if err != nil {
+		return fmt.Errorf("Error retrieving server details: %s", err)
+	}
+
+	policies := make([]brightbox.FirewallPolicy, 0, len(server.ServerGroups))
+	for _, group := range server.ServerGroups {
+		server_group, err := client.ServerGroup(group.Id)
+		if err != nil {
+			return fmt.Errorf("Error retrieving Server Group details: %s", err)
+		}
+		if server_group.FirewallPolicy == nil {
+			log.Printf("[DEBUG] No firewall policy applied to server group %s", server_group.Id)
+			continue
+		}
+		firewall_policy, err := client.FirewallPolicy(server_group.FirewallPolicy.Id)
+		if err != nil {
+			return fmt.Errorf("Error retrieving Firewall Policy details: %s", err)
+		}
+		if firewall_policy.ServerGroup == nil {
+			firewall_policy.ServerGroup = &brightbox.ServerGroup{Id: server_group.Id}
+		}
+		policies = append(policies, *firewall_policy)
+	}
+
+	d.SetId(server.Id)
+	d.Set("server_groups", schema.NewSet(schema.HashString, flattenServerGroups(server.ServerGroups)))
+	policyIds := make([]interface{}, len(policies))
+	for i, policy := range policies {
+		policyIds[i] = policy.Id
+	}
+	d.Set("firewall_policies", schema.NewSet(schema.HashString, policyIds))
+	return d.Set("rule", flattenEffectiveFirewallRules(policies))
+}
+
+// Combine the rules from each policy into a single list, dropping
+// duplicates and ordering by rule id so the result is stable between reads.
+func flattenEffectiveFirewallRules(
+	policies []brightbox.FirewallPolicy,
+) []map[string]interface{} {
+	seen := make(map[string]bool)
+	var rules []map[string]interface{}
+	for _, policy := range policies {
+		server_group := ""
+		if policy.ServerGroup != nil {
+			server_group = policy.ServerGroup.Id
+		}
+		for _, rule := range policy.Rules {
+			if seen[rule.Id] {
+				continue
+			}
+			seen[rule.Id] = true
+			rules = append(rules, map[string]interface{}{
+				"id":               rule.Id,
+				"firewall_policy":  policy.Id,
+				"server_group":     server_group,
+				"protocol":         rule.Protocol,
+				"source":           rule.Source,
+				"source_port":      rule.SourcePort,
+				"destination":      rule.Destination,
+				"destination_port": rule.DestinationPort,
+				"icmp_type_name":   rule.IcmpTypeName,
+				"description":      rule.Description,
+			})
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i]["id"].(string) < rules[j]["id"].(string)
+	})
+	return rules
+}
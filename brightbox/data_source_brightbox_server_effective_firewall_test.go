@@ -0,0 +1,118 @@
+package brightbox
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataServerEffectiveFirewall_basic(t *testing.T) {
+	var server brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerAndGroupDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDataServerEffectiveFirewallConfig_basic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &server),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server_effective_firewall.foobar", "server_groups.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server_effective_firewall.foobar", "firewall_policies.#", "2"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server_effective_firewall.foobar", "rule.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestFlattenEffectiveFirewallRules(t *testing.T) {
+	policies := []brightbox.FirewallPolicy{
+		{
+			Id:          "fwp-bbbbb",
+			ServerGroup: &brightbox.ServerGroup{Id: "grp-bbbbb"},
+			Rules: []brightbox.FirewallRule{
+				{Id: "fwr-ccccc", Protocol: "tcp", DestinationPort: "443"},
+				{Id: "fwr-aaaaa", Protocol: "tcp", DestinationPort: "22"},
+			},
+		},
+		{
+			Id:          "fwp-aaaaa",
+			ServerGroup: &brightbox.ServerGroup{Id: "grp-aaaaa"},
+			Rules: []brightbox.FirewallRule{
+				{Id: "fwr-bbbbb", Protocol: "icmp", IcmpTypeName: "echo-request"},
+				{Id: "fwr-aaaaa", Protocol: "tcp", DestinationPort: "22"},
+			},
+		},
+		{
+			Id: "fwp-empty",
+		},
+	}
+	rules := flattenEffectiveFirewallRules(policies)
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d: %#v", len(rules), rules)
+	}
+	expected := []struct {
+		id     string
+		policy string
+		group  string
+	}{
+		{"fwr-aaaaa", "fwp-bbbbb", "grp-bbbbb"},
+		{"fwr-bbbbb", "fwp-aaaaa", "grp-aaaaa"},
+		{"fwr-ccccc", "fwp-bbbbb", "grp-bbbbb"},
+	}
+	for i, example := range expected {
+		if rules[i]["id"] != example.id {
+			t.Errorf("Rule %d: got id %q, expected %q", i, rules[i]["id"], example.id)
+		}
+		if rules[i]["firewall_policy"] != example.policy {
+			t.Errorf("Rule %d: got policy %q, expected %q", i, rules[i]["firewall_policy"], example.policy)
+		}
+		if rules[i]["server_group"] != example.group {
+			t.Errorf("Rule %d: got server group %q, expected %q", i, rules[i]["server_group"], example.group)
+		}
+	}
+}
+
+func testAccCheckBrightboxDataServerEffectiveFirewallConfig_basic(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+resource "brightbox_firewall_policy" "barfoo" {
+	name = "bar-%d"
+	server_group = "${brightbox_server_group.barfoo.id}"
+}
+
+resource "brightbox_firewall_policy" "barfoo2" {
+	name = "baz-%d"
+	server_group = "${brightbox_server_group.barfoo2.id}"
+}
+
+resource "brightbox_firewall_rule" "ssh" {
+	firewall_policy = "${brightbox_firewall_policy.barfoo.id}"
+	protocol = "tcp"
+	source = "any"
+	destination_port = 22
+}
+
+resource "brightbox_firewall_rule" "https" {
+	firewall_policy = "${brightbox_firewall_policy.barfoo2.id}"
+	protocol = "tcp"
+	source = "any"
+	destination_port = 443
+}
+
+data "brightbox_server_effective_firewall" "foobar" {
+	server = "${brightbox_server.foobar.id}"
+	depends_on = ["brightbox_firewall_rule.ssh", "brightbox_firewall_rule.https"]
+}
+`, testAccCheckBrightboxServerConfig_multi_server_group(rInt), rInt, rInt)
+}
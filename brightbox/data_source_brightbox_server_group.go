@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"sort"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
@@ -25,6 +26,49 @@ func dataSourceBrightboxServerGroup() *schema.Resource {
 				Optional: true,
 				Computed: true,
 			},
+
+			"default": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+
+			//Computed Values
+			"firewall_policy": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"servers": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"members": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hostname": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -49,9 +93,35 @@ func dataSourceBrightboxServerGroupRead(
 
 	log.Printf("[DEBUG] Single Server Group found: %s", group.Id)
 	d.SetId(group.Id)
+	servers := serverIdList(group.Servers)
+	sort.Strings(servers)
+	if err := d.Set("servers", servers); err != nil {
+		return fmt.Errorf("Error setting servers: %s", err)
+	}
+	if err := d.Set("members", flattenServerGroupMembers(group.Servers)); err != nil {
+		return fmt.Errorf("Error setting members: %s", err)
+	}
 	return setServerGroupAttributes(d, group)
 }
 
+// List the servers in a group in the same order as servers, with the
+// details needed to manage them together, such as renaming them all.
+func flattenServerGroupMembers(servers []brightbox.Server) []interface{} {
+	members := make([]interface{}, len(servers))
+	for i, server := range servers {
+		members[i] = map[string]interface{}{
+			"id":       server.Id,
+			"name":     server.Name,
+			"status":   server.Status,
+			"hostname": server.Hostname,
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].(map[string]interface{})["id"].(string) < members[j].(map[string]interface{})["id"].(string)
+	})
+	return members
+}
+
 func findGroupByFilter(
 	serverGroups []brightbox.ServerGroup,
 	d *schema.ResourceData,
@@ -98,5 +168,10 @@ func serverGroupMatch(
 	if ok && !descRe.MatchString(serverGroup.Description) {
 		return false
 	}
+	// GetOk treats false as unset, so default = false needs GetOkExists
+	isDefault, ok := d.GetOkExists("default")
+	if ok && serverGroup.Default != isDefault.(bool) {
+		return false
+	}
 	return true
 }
@@ -2,9 +2,13 @@ package brightbox
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -19,12 +23,78 @@ func TestAccBrightboxDataServerGroup_basic(t *testing.T) {
 					testAccCheckDataServerGroupDataSourceID("data.brightbox_server_group.default"),
 					resource.TestCheckResourceAttr(
 						"data.brightbox_server_group.default", "name", "default"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server_group.default", "default", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBrightboxDataServerGroup_servers(t *testing.T) {
+	var server brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerAndGroupDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDataServerGroupConfig_servers(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &server),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server_group.barfoo", "servers.#", "1"),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_server_group.barfoo", "servers.0",
+						"brightbox_server.foobar", "id"),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_server_group.barfoo", "members.0.name",
+						"brightbox_server.foobar", "name"),
 				),
 			},
 		},
 	})
 }
 
+func TestFlattenServerGroupMembers(t *testing.T) {
+	members := flattenServerGroupMembers([]brightbox.Server{
+		{Id: "srv-bbbbb", Name: "web-2", Status: "active", Hostname: "srv-bbbbb"},
+		{Id: "srv-aaaaa", Name: "web-1", Status: "inactive", Hostname: "srv-aaaaa"},
+	})
+	expected := []interface{}{
+		map[string]interface{}{"id": "srv-aaaaa", "name": "web-1", "status": "inactive", "hostname": "srv-aaaaa"},
+		map[string]interface{}{"id": "srv-bbbbb", "name": "web-2", "status": "active", "hostname": "srv-bbbbb"},
+	}
+	if !reflect.DeepEqual(members, expected) {
+		t.Errorf("Got %v, expected %v", members, expected)
+	}
+}
+
+func TestServerGroupMatch_default(t *testing.T) {
+	defaultGroup := &brightbox.ServerGroup{Id: "grp-aaaaa", Name: "default", Default: true}
+	otherGroup := &brightbox.ServerGroup{Id: "grp-bbbbb", Name: "web"}
+	examples := []struct {
+		raw          map[string]interface{}
+		matchDefault bool
+		matchOther   bool
+	}{
+		{map[string]interface{}{}, true, true},
+		{map[string]interface{}{"default": true}, true, false},
+		{map[string]interface{}{"default": false}, false, true},
+	}
+	for _, example := range examples {
+		d := schema.TestResourceDataRaw(t, dataSourceBrightboxServerGroup().Schema, example.raw)
+		if got := serverGroupMatch(defaultGroup, d, nil, nil); got != example.matchDefault {
+			t.Errorf("%v: expected default group match %t, got %t", example.raw, example.matchDefault, got)
+		}
+		if got := serverGroupMatch(otherGroup, d, nil, nil); got != example.matchOther {
+			t.Errorf("%v: expected other group match %t, got %t", example.raw, example.matchOther, got)
+		}
+	}
+}
+
 func testAccCheckDataServerGroupDataSourceID(n string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[n]
@@ -45,3 +115,14 @@ data "brightbox_server_group" "default" {
 	name = "^default$"
 }
 `
+
+func testAccCheckBrightboxDataServerGroupConfig_servers(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+data "brightbox_server_group" "barfoo" {
+	name = "^bar-%d$"
+	depends_on = ["brightbox_server.foobar"]
+}
+`, testAccCheckBrightboxServerConfig_server_group(rInt), rInt)
+}
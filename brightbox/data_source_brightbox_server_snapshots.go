@@ -0,0 +1,94 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceBrightboxServerSnapshots() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxServerSnapshotsRead,
+
+		Schema: map[string]*schema.Schema{
+			"server": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			//Computed Values
+			"snapshots": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"created_at": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxServerSnapshotsRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	server_id := d.Get("server").(string)
+	log.Printf("[DEBUG] Server snapshots data read called for server %s", server_id)
+	images, err := client.Images()
+	if err != nil {
+		return fmt.Errorf("Error retrieving image list: %s", err)
+	}
+
+	d.SetId(server_id)
+	return d.Set("snapshots", flattenServerSnapshots(images, server_id))
+}
+
+// Select the images taken from the server, newest first, so the most
+// recent snapshot is always the first element of the list.
+func flattenServerSnapshots(
+	images []brightbox.Image,
+	server_id string,
+) []map[string]interface{} {
+	var snapshots []brightbox.Image
+	for _, image := range images {
+		if image.Source == server_id && image.Status != "deleted" {
+			snapshots = append(snapshots, image)
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	result := make([]map[string]interface{}, len(snapshots))
+	for i, snapshot := range snapshots {
+		result[i] = map[string]interface{}{
+			"id":         snapshot.Id,
+			"name":       snapshot.Name,
+			"created_at": snapshot.CreatedAt.Format(time.RFC3339),
+			"status":     snapshot.Status,
+		}
+	}
+	return result
+}
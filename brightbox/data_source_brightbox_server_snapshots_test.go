@@ -0,0 +1,65 @@
+package brightbox
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataServerSnapshots_basic(t *testing.T) {
+	var server brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDataServerSnapshotsConfig_basic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &server),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_server_snapshots.foobar", "id",
+						"brightbox_server.foobar", "id"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server_snapshots.foobar", "snapshots.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestFlattenServerSnapshots(t *testing.T) {
+	now := time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC)
+	images := []brightbox.Image{
+		{Id: "img-aaaaa", Source: "srv-12345", Status: "available", CreatedAt: now.Add(-time.Hour)},
+		{Id: "img-bbbbb", Source: "srv-54321", Status: "available", CreatedAt: now},
+		{Id: "img-ccccc", Source: "srv-12345", Status: "available", CreatedAt: now},
+		{Id: "img-ddddd", Source: "srv-12345", Status: "deleted", CreatedAt: now},
+	}
+	snapshots := flattenServerSnapshots(images, "srv-12345")
+	if len(snapshots) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(snapshots))
+	}
+	if snapshots[0]["id"] != "img-ccccc" || snapshots[1]["id"] != "img-aaaaa" {
+		t.Errorf("Expected newest snapshot first, got %v", snapshots)
+	}
+	if got := snapshots[0]["created_at"]; got != "2019-10-01T12:00:00Z" {
+		t.Errorf("Got created_at %q", got)
+	}
+}
+
+func testAccCheckBrightboxDataServerSnapshotsConfig_basic(rInt int) string {
+	return fmt.Sprintf(`
+%s
+
+data "brightbox_server_snapshots" "foobar" {
+	server = "${brightbox_server.foobar.id}"
+}
+`, testAccCheckBrightboxServerConfig_basic(rInt))
+}
@@ -0,0 +1,131 @@
+package brightbox
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataServer_basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	name := fmt.Sprintf("foo-%d", rInt)
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDataServerConfig_basic(name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_server.by_name", "id",
+						"brightbox_server.foobar", "id"),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_server.by_id", "fqdn",
+						"brightbox_server.foobar", "fqdn"),
+					resource.TestCheckResourceAttrPair(
+						"data.brightbox_server.by_id", "ipv6_address",
+						"brightbox_server.foobar", "ipv6_address"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server.by_id", "status", "active"),
+				),
+			},
+		},
+	})
+}
+
+func TestFindServerByName(t *testing.T) {
+	servers := []brightbox.Server{
+		{Id: "srv-aaaaa", Name: "web", Status: "active"},
+		{Id: "srv-bbbbb", Name: "web", Status: "deleted"},
+		{Id: "srv-ccccc", Name: "db", Status: "active"},
+		{Id: "srv-ddddd", Name: "db", Status: "inactive"},
+	}
+	server, err := findServerByName(servers, "web")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if server.Id != "srv-aaaaa" {
+		t.Errorf("Got server %q, expected srv-aaaaa", server.Id)
+	}
+	if _, err := findServerByName(servers, "db"); err == nil {
+		t.Errorf("Expected an error for a name shared by two servers")
+	}
+	if _, err := findServerByName(servers, "we"); err == nil {
+		t.Errorf("Expected an error when no server matches")
+	}
+}
+
+func TestDataSourceBrightboxServerRead(t *testing.T) {
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1.0/servers":
+			w.Write([]byte(`[{"id":"srv-12345","name":"web","status":"active"}]`))
+		case "/1.0/servers/srv-12345":
+			w.Write([]byte(`{"id":"srv-12345","name":"web","status":"active",
+				"fqdn":"srv-12345.gb1.brightbox.com",
+				"image":{"id":"img-12345","username":"ubuntu"},
+				"interfaces":[{"id":"int-12345","ipv6_address":"2a02:1348::1"}],
+				"cloud_ips":[{"id":"cip-12345","public_ip":"109.107.1.1"}],
+				"server_groups":[{"id":"grp-12345"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer server.Close()
+
+	d := dataSourceBrightboxServer().Data(nil)
+	d.Set("name", "web")
+	if err := dataSourceBrightboxServerRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "srv-12345" {
+		t.Errorf("Got id %q, expected srv-12345", d.Id())
+	}
+	expected := map[string]string{
+		"fqdn":         "srv-12345.gb1.brightbox.com",
+		"ipv4_address": "109.107.1.1",
+		"ipv6_address": "2a02:1348::1",
+		"status":       "active",
+		"image":        "img-12345",
+	}
+	for key, value := range expected {
+		if got := d.Get(key).(string); got != value {
+			t.Errorf("Got %s %q, expected %q", key, got, value)
+		}
+	}
+	if got := d.Get("server_groups.#").(int); got != 1 {
+		t.Errorf("Expected one server group, got %d", got)
+	}
+
+	d = dataSourceBrightboxServer().Data(nil)
+	d.Set("name", "db")
+	if err := dataSourceBrightboxServerRead(d, meta); err == nil {
+		t.Errorf("Expected an error when no server matches")
+	}
+}
+
+func testAccCheckBrightboxDataServerConfig_basic(name string) string {
+	return fmt.Sprintf(`
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "%s"
+	type = "1gb.ssd"
+}
+
+data "brightbox_server" "by_name" {
+	name = "${brightbox_server.foobar.name}"
+}
+
+data "brightbox_server" "by_id" {
+	id = "${brightbox_server.foobar.id}"
+}
+
+%s
+`, name, TestAccBrightboxImageDataSourceConfig_blank_disk)
+}
@@ -0,0 +1,184 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceBrightboxServerType() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxServerTypeRead,
+
+		Schema: map[string]*schema.Schema{
+
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"family": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"ram": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"cores": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"handle": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"disk_size": {
+				Type:     schema.TypeInt,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"status": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxServerTypeRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	log.Printf("[DEBUG] ServerType data read called. Retrieving server type list")
+
+	serverTypes, err := client.ServerTypes()
+	if err != nil {
+		return fmt.Errorf("Error retrieving server type list: %s", err)
+	}
+
+	serverType, err := findServerTypeByFilter(serverTypes, d)
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Single ServerType found: %s", serverType.Id)
+	return dataSourceBrightboxServerTypesAttributes(d, serverType)
+}
+
+func dataSourceBrightboxServerTypesAttributes(
+	d *schema.ResourceData,
+	serverType *brightbox.ServerType,
+) error {
+	log.Printf("[DEBUG] serverType details: %#v", serverType)
+
+	d.SetId(serverType.Id)
+	d.Set("name", serverType.Name)
+	d.Set("handle", serverType.Handle)
+	d.Set("family", serverTypeFamily(serverType.Handle))
+	d.Set("ram", serverType.Ram)
+	d.Set("cores", serverType.Cores)
+	d.Set("disk_size", serverType.DiskSize)
+	d.Set("status", serverType.Status)
+
+	return nil
+}
+
+// Server type handles are a size followed by the family, such as
+// "4gb.ssd" or "8gb.high-io". Handles without a size are their own
+// family.
+func serverTypeFamily(handle string) string {
+	parts := strings.SplitN(handle, ".", 2)
+	return parts[len(parts)-1]
+}
+
+func findServerTypeByFilter(
+	serverTypes []brightbox.ServerType,
+	d *schema.ResourceData,
+) (*brightbox.ServerType, error) {
+	nameRe, err := regexp.Compile(d.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []brightbox.ServerType
+	for _, serverType := range serverTypes {
+		if serverTypeMatch(&serverType, d, nameRe) {
+			results = append(results, serverType)
+		}
+	}
+	if len(results) == 1 {
+		return &results[0], nil
+	} else if len(results) > 1 {
+		return nil, fmt.Errorf("Your query returned more than one result (found %d entries). Please try a more "+
+			"specific search criteria.", len(results))
+	} else {
+		return nil, fmt.Errorf("Your query returned no results. " +
+			"Please change your search criteria and try again.")
+	}
+}
+
+// Match on the search filter - if the elements exist
+func serverTypeMatch(
+	serverType *brightbox.ServerType,
+	d *schema.ResourceData,
+	nameRe *regexp.Regexp,
+) bool {
+	status, ok := d.GetOk("status")
+	if !ok {
+		status = "available"
+	}
+	if serverType.Status != status.(string) {
+		return false
+	}
+	_, ok = d.GetOk("name")
+	if ok && !nameRe.MatchString(serverType.Name) {
+		return false
+	}
+	handle, ok := d.GetOk("handle")
+	if ok && serverType.Handle != handle.(string) {
+		return false
+	}
+	family, ok := d.GetOk("family")
+	if ok && serverTypeFamily(serverType.Handle) != family.(string) {
+		return false
+	}
+	ram, ok := d.GetOk("ram")
+	if ok && serverType.Ram != ram.(int) {
+		return false
+	}
+	cores, ok := d.GetOk("cores")
+	if ok && serverType.Cores != cores.(int) {
+		return false
+	}
+	disk_size, ok := d.GetOk("disk_size")
+	if ok && serverType.DiskSize != disk_size.(int) {
+		return false
+	}
+	return true
+}
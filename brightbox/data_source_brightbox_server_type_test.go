@@ -0,0 +1,96 @@
+package brightbox
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+var serverTypeRe = regexp.MustCompile("^typ-.....$")
+
+func TestAccBrightboxServerType_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: TestAccBrightboxServerTypeConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.brightbox_server_type.foobar", "id", serverTypeRe),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server_type.foobar", "handle", "4gb.ssd"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_server_type.foobar", "family", "ssd"),
+				),
+			},
+		},
+	})
+}
+
+func TestServerTypeFamily(t *testing.T) {
+	var familyTests = []struct {
+		handle   string
+		expected string
+	}{
+		{"4gb.ssd", "ssd"},
+		{"8gb.high-io", "high-io"},
+		{"nano", "nano"},
+	}
+	for _, example := range familyTests {
+		if got := serverTypeFamily(example.handle); got != example.expected {
+			t.Errorf("%q: got %q, expected %q", example.handle, got, example.expected)
+		}
+	}
+}
+
+func TestFindServerTypeByFilter(t *testing.T) {
+	serverTypes := []brightbox.ServerType{
+		{Id: "typ-aaaaa", Handle: "4gb.ssd", Ram: 4096, Cores: 2, Status: "available"},
+		{Id: "typ-bbbbb", Handle: "4gb.high-io", Ram: 4096, Cores: 2, Status: "available"},
+		{Id: "typ-ccccc", Handle: "8gb.high-io", Ram: 8192, Cores: 4, Status: "available"},
+		{Id: "typ-ddddd", Handle: "4gb.old", Ram: 4096, Cores: 2, DiskSize: 81920, Status: "deprecated"},
+	}
+	d := dataSourceBrightboxServerType().Data(nil)
+	d.Set("family", "high-io")
+	d.Set("ram", 4096)
+	result, err := findServerTypeByFilter(serverTypes, d)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result.Id != "typ-bbbbb" {
+		t.Errorf("Got %s, expected typ-bbbbb", result.Id)
+	}
+
+	d = dataSourceBrightboxServerType().Data(nil)
+	d.Set("ram", 4096)
+	if _, err := findServerTypeByFilter(serverTypes, d); err == nil {
+		t.Errorf("Expected an error when more than one family matches")
+	}
+
+	d = dataSourceBrightboxServerType().Data(nil)
+	d.Set("handle", "8gb.high-io")
+	if result, err := findServerTypeByFilter(serverTypes, d); err != nil || result.Id != "typ-ccccc" {
+		t.Errorf("Expected typ-ccccc by handle, got %v, %v", result, err)
+	}
+
+	d = dataSourceBrightboxServerType().Data(nil)
+	d.Set("handle", "4gb.old")
+	if _, err := findServerTypeByFilter(serverTypes, d); err == nil {
+		t.Errorf("Expected deprecated types to be skipped by default")
+	}
+	d.Set("status", "deprecated")
+	d.Set("disk_size", 81920)
+	if result, err := findServerTypeByFilter(serverTypes, d); err != nil || result.Id != "typ-ddddd" {
+		t.Errorf("Expected typ-ddddd when asking for deprecated types, got %v, %v", result, err)
+	}
+}
+
+const TestAccBrightboxServerTypeConfig_basic = `
+data "brightbox_server_type" "foobar" {
+	family = "ssd"
+	ram    = 4096
+}
+`
@@ -0,0 +1,64 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceBrightboxZone() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxZoneRead,
+
+		Schema: map[string]*schema.Schema{
+			"handle": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+
+			//Computed Values
+			"region": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxZoneRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	log.Printf("[DEBUG] Zone data read called. Retrieving zone list")
+	zones, err := client.Zones()
+	if err != nil {
+		return fmt.Errorf("Error retrieving zone list: %s", err)
+	}
+
+	zone, err := findZoneByHandle(zones, d.Get("handle").(string))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[DEBUG] Single Zone found: %s", zone.Id)
+	d.SetId(zone.Id)
+	d.Set("handle", zone.Handle)
+	d.Set("region", zoneRegion(zone.Handle))
+	return nil
+}
+
+func findZoneByHandle(
+	zones []brightbox.Zone,
+	handle string,
+) (*brightbox.Zone, error) {
+	for _, zone := range zones {
+		if zone.Handle == handle {
+			return &zone, nil
+		}
+	}
+	return nil, fmt.Errorf("Your query returned no results. No zone has the handle %q.", handle)
+}
@@ -0,0 +1,52 @@
+package brightbox
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataZone_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBrightboxDataZoneConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr(
+						"data.brightbox_zone.gb1_a", "id", regexp.MustCompile("^zon-.....$")),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_zone.gb1_a", "handle", "gb1-a"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_zone.gb1_a", "region", "gb1"),
+				),
+			},
+		},
+	})
+}
+
+func TestFindZoneByHandle(t *testing.T) {
+	zones := []brightbox.Zone{
+		{Id: "zon-aaaaa", Handle: "gb1-a"},
+		{Id: "zon-bbbbb", Handle: "gb1-b"},
+	}
+	zone, err := findZoneByHandle(zones, "gb1-b")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if zone.Id != "zon-bbbbb" {
+		t.Errorf("Got zone %q, expected zon-bbbbb", zone.Id)
+	}
+	if _, err := findZoneByHandle(zones, "gb1"); err == nil {
+		t.Errorf("Expected an error when no zone matches")
+	}
+}
+
+const testAccBrightboxDataZoneConfig_basic = `
+data "brightbox_zone" "gb1_a" {
+	handle = "gb1-a"
+}
+`
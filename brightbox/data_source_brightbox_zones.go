@@ -0,0 +1,131 @@
+package brightbox
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func dataSourceBrightboxZones() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceBrightboxZonesRead,
+
+		Schema: map[string]*schema.Schema{
+			"region": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			//Computed Values
+			"zones": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"handle": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"region": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
+			"handles": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"regions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceBrightboxZonesRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	log.Printf("[DEBUG] Zones data read called")
+	zones, err := client.Zones()
+	if err != nil {
+		return fmt.Errorf("Error retrieving zone list: %s", err)
+	}
+
+	region := d.Get("region").(string)
+	zones = filterZonesByRegion(zones, region)
+	if len(zones) == 0 {
+		return fmt.Errorf("No zones found in region %q", region)
+	}
+
+	var handles []string
+	regions := map[string]bool{}
+	flattened := make([]map[string]interface{}, len(zones))
+	for i, zone := range zones {
+		handles = append(handles, zone.Handle)
+		regions[zoneRegion(zone.Handle)] = true
+		flattened[i] = map[string]interface{}{
+			"id":     zone.Id,
+			"handle": zone.Handle,
+			"region": zoneRegion(zone.Handle),
+		}
+	}
+	var region_list []string
+	for name := range regions {
+		region_list = append(region_list, name)
+	}
+	sort.Strings(region_list)
+
+	sum := sha1.Sum([]byte(strings.Join(handles, ",")))
+	d.SetId(hex.EncodeToString(sum[:]))
+	if err := d.Set("zones", flattened); err != nil {
+		return fmt.Errorf("Error setting zones: %s", err)
+	}
+	d.Set("handles", handles)
+	d.Set("regions", region_list)
+	return nil
+}
+
+// The API has no separate region object. Zone handles are the region
+// name followed by the zone letter, e.g. gb1-a, and each zone is its
+// own failure domain.
+func zoneRegion(handle string) string {
+	if i := strings.LastIndex(handle, "-"); i > 0 {
+		return handle[:i]
+	}
+	return handle
+}
+
+// Select the zones in the region, sorted by handle so the list and
+// the count of zones are stable between reads.
+func filterZonesByRegion(zones []brightbox.Zone, region string) []brightbox.Zone {
+	var result []brightbox.Zone
+	for _, zone := range zones {
+		if region == "" || zoneRegion(zone.Handle) == region {
+			result = append(result, zone)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Handle < result[j].Handle
+	})
+	return result
+}
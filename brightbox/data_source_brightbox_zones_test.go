@@ -0,0 +1,58 @@
+package brightbox
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccBrightboxDataZones_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBrightboxDataZonesConfig_basic,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"data.brightbox_zones.gb1", "regions.#", "1"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_zones.gb1", "regions.0", "gb1"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_zones.gb1", "handles.0", "gb1-a"),
+					resource.TestCheckResourceAttr(
+						"data.brightbox_zones.gb1", "zones.0.region", "gb1"),
+				),
+			},
+		},
+	})
+}
+
+func TestFilterZonesByRegion(t *testing.T) {
+	zones := []brightbox.Zone{
+		{Id: "zon-bbbbb", Handle: "gb1-b"},
+		{Id: "zon-ccccc", Handle: "gb2-a"},
+		{Id: "zon-aaaaa", Handle: "gb1-a"},
+	}
+	var handles []string
+	for _, zone := range filterZonesByRegion(zones, "gb1") {
+		handles = append(handles, zone.Handle)
+	}
+	if expected := []string{"gb1-a", "gb1-b"}; !reflect.DeepEqual(handles, expected) {
+		t.Errorf("Got zones %v, expected %v", handles, expected)
+	}
+	if got := len(filterZonesByRegion(zones, "")); got != 3 {
+		t.Errorf("Expected all zones without a region, got %d", got)
+	}
+	if got := zoneRegion("gb1s-a"); got != "gb1s" {
+		t.Errorf("Got region %q, expected gb1s", got)
+	}
+}
+
+const testAccBrightboxDataZonesConfig_basic = `
+data "brightbox_zones" "gb1" {
+	region = "gb1"
+}
+`
@@ -1,10 +1,12 @@
 package brightbox
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
 func TestAccBrightboxFirewallPolicy_importBasic(t *testing.T) {
@@ -49,3 +51,43 @@ func TestAccBrightboxFirewallPolicy_importEmpty(t *testing.T) {
 		},
 	})
 }
+
+func TestAccBrightboxFirewallPolicy_importWithRules(t *testing.T) {
+	resourceName := "brightbox_firewall_policy.terraform"
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxFirewallRuleAndPolicyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxFirewallRuleConfig_basic(rInt),
+			},
+
+			{
+				ResourceName:     resourceName,
+				ImportState:      true,
+				ImportStateCheck: testAccCheckBrightboxFirewallPolicyImportedRules(1),
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxFirewallPolicyImportedRules(count int) resource.ImportStateCheckFunc {
+	return func(s []*terraform.InstanceState) error {
+		if len(s) != count+1 {
+			return fmt.Errorf("Expected %d imported resources, got %d", count+1, len(s))
+		}
+		rules := 0
+		for _, state := range s {
+			if state.Ephemeral.Type == "brightbox_firewall_rule" {
+				rules++
+			}
+		}
+		if rules != count {
+			return fmt.Errorf("Expected %d imported firewall rules, got %d", count, rules)
+		}
+		return nil
+	}
+}
@@ -1,9 +1,13 @@
 package brightbox
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/containers"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 )
 
 func TestAccBrightboxOrbitContainer_importBasic(t *testing.T) {
@@ -49,3 +53,58 @@ func TestAccBrightboxOrbitContainer_importMetadata(t *testing.T) {
 		},
 	})
 }
+
+func TestAccBrightboxOrbitContainer_importSettings(t *testing.T) {
+	resourceName := "brightbox_orbit_container.foobar"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxOrbitContainerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxOrbitContainerConfig_settings,
+			},
+
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"created_at"},
+			},
+		},
+	})
+}
+
+// An imported container has no configuration to go by, so everything
+// must come from the container's headers.
+func TestSetContainerAttributes_import(t *testing.T) {
+	d := resourceBrightboxContainer().Data(nil)
+	d.SetId("initial")
+	header := &containers.GetHeader{
+		Read:             []string{".r:*", " .rlistings"},
+		Write:            []string{"acc-12345:*"},
+		VersionsLocation: "initial_versions",
+		Date:             time.Date(2019, 7, 1, 9, 0, 0, 0, time.UTC),
+	}
+	metadata := map[string]string{"Quota-Bytes": "1048576", "Team": "web"}
+	if err := setContainerAttributes(d, header, metadata, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("name").(string); got != "initial" {
+		t.Errorf("Got name %q", got)
+	}
+	if got := map_from_string_set(d, "container_read"); len(got) != 2 || !d.Get("container_read").(*schema.Set).Contains(".rlistings") {
+		t.Errorf("Got container_read %v", got)
+	}
+	if got := map_from_string_set(d, "container_write"); !reflect.DeepEqual(got, []string{"acc-12345:*"}) {
+		t.Errorf("Got container_write %v", got)
+	}
+	if got := d.Get("versions_location").(string); got != "initial_versions" {
+		t.Errorf("Got versions_location %q", got)
+	}
+	expected := map[string]interface{}{"quota-bytes": "1048576", "team": "web"}
+	if got := d.Get("metadata").(map[string]interface{}); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got metadata %v, expected %v", got, expected)
+	}
+}
@@ -3,10 +3,13 @@ package brightbox
 import (
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -16,8 +19,56 @@ const (
 	defaultClientSecret = "uogoelzgt0nwawb"
 	appPrefix           = "app-"
 	passwordEnvVar      = "BRIGHTBOX_PASSWORD"
+	otpEnvVar           = "BRIGHTBOX_OTP"
+	defaultDialTimeout  = "30s"
+	defaultKeepAlive    = "30s"
+	defaultHTTPTimeout  = "60s"
+	defaultMaxRetries   = 5
 )
 
+const defaultRegion = "gb1"
+
+type regionEndpoint struct {
+	ApiURL   string
+	OrbitURL string
+}
+
+// Endpoints used for each region unless apiurl or orbit_url are given
+var regionEndpoints = map[string]regionEndpoint{
+	"gb1": {
+		ApiURL:   brightbox.DefaultRegionApiURL,
+		OrbitURL: brightbox.DefaultOrbitAuthURL,
+	},
+	"gb1s": {
+		ApiURL:   "https://api.gb1s.brightbox.com/",
+		OrbitURL: "https://orbit.gb1s.brightbox.com/v1/",
+	},
+}
+
+// Fill in any endpoint URLs not given explicitly from the region
+func (c *authdetails) setRegionEndpoints(region string) error {
+	endpoint, ok := regionEndpoints[region]
+	if !ok {
+		return fmt.Errorf("Unknown region %q, expected one of %s", region, strings.Join(regionNames(), ", "))
+	}
+	if c.APIURL == "" {
+		c.APIURL = endpoint.ApiURL
+	}
+	if c.OrbitUrl == "" {
+		c.OrbitUrl = endpoint.OrbitURL
+	}
+	return nil
+}
+
+func regionNames() []string {
+	names := make([]string, 0, len(regionEndpoints))
+	for name := range regionEndpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func Provider() terraform.ResourceProvider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
@@ -46,40 +97,155 @@ func Provider() terraform.ResourceProvider {
 				DefaultFunc: schema.EnvDefaultFunc(passwordEnvVar, nil),
 				Description: "Brightbox Cloud Password for User Name",
 			},
+			"otp": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc(otpEnvVar, nil),
+				Description: "Brightbox Cloud One Time Password for accounts with two factor authentication",
+			},
 			"account": {
 				Type:        schema.TypeString,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("BRIGHTBOX_ACCOUNT", nil),
 				Description: "Brightbox Cloud Account to operate on",
 			},
+			"region": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_REGION", defaultRegion),
+				ValidateFunc: validation.StringInSlice(regionNames(), false),
+				Description:  "Brightbox Cloud Region, used to select the Api and Orbit URLs",
+			},
 			"apiurl": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("BRIGHTBOX_API_URL", brightbox.DefaultRegionApiURL),
+				DefaultFunc: schema.EnvDefaultFunc("BRIGHTBOX_API_URL", nil),
 				Description: "Brightbox Cloud Api URL for selected Region",
 			},
 			"orbit_url": {
 				Type:        schema.TypeString,
 				Optional:    true,
-				DefaultFunc: schema.EnvDefaultFunc("BRIGHTBOX_ORBIT_URL", brightbox.DefaultOrbitAuthURL),
+				DefaultFunc: schema.EnvDefaultFunc("BRIGHTBOX_ORBIT_URL", nil),
 				Description: "Brightbox Cloud Orbit URL for selected Region",
 			},
+			"max_concurrent_requests": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_MAX_CONCURRENT_REQUESTS", 0),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Maximum number of simultaneous Brightbox Cloud API requests. Zero means no limit",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_MAX_RETRIES", defaultMaxRetries),
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of times to retry an API request that is rate limited or fails with a server error",
+			},
+			"user_data_limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_USER_DATA_LIMIT", userdata_size_limit),
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Largest server user data accepted, in bytes after base64 encoding",
+			},
+			"default_metadata": {
+				Type:         schema.TypeMap,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ValidateFunc: http1Keys,
+				Description:  "Metadata added to every resource that supports it. Resource metadata takes precedence",
+			},
+			"allowed_server_groups": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(serverGroupIdRe, "must be a valid server group ID"),
+				},
+				Set:         schema.HashString,
+				Description: "Server groups that servers may be placed in. Any group is allowed if unset",
+			},
+			"token_cache_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("BRIGHTBOX_TOKEN_CACHE_FILE", ""),
+				Description: "File to keep the OAuth token in between runs. Unset means no caching",
+			},
+			"name_prefix": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_NAME_PREFIX", nil),
+				ValidateFunc: validation.StringLenBetween(1, 64),
+				Description:  "Prefix of the names given to servers, load balancers and database servers created without one",
+			},
+			"default_create_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_DEFAULT_CREATE_TIMEOUT", nil),
+				ValidateFunc: ValidateDurationString,
+				Description:  "How long to wait for resources to be created, unless the resource sets its own timeout",
+			},
+			"default_delete_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_DEFAULT_DELETE_TIMEOUT", nil),
+				ValidateFunc: ValidateDurationString,
+				Description:  "How long to wait for resources to be deleted, unless the resource sets its own timeout",
+			},
+			"dial_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_DIAL_TIMEOUT", defaultDialTimeout),
+				ValidateFunc: ValidateDurationString,
+				Description:  "How long to wait for a connection to Brightbox Cloud to be established",
+			},
+			"keepalive": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_KEEPALIVE", defaultKeepAlive),
+				ValidateFunc: ValidateDurationString,
+				Description:  "Interval between TCP keepalive probes on connections to Brightbox Cloud",
+			},
+			"http_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("BRIGHTBOX_HTTP_TIMEOUT", defaultHTTPTimeout),
+				ValidateFunc: ValidateDurationString,
+				Description:  "How long a single request to Brightbox Cloud may take. Zero or less means no limit",
+			},
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"brightbox_image":         dataSourceBrightboxImage(),
-			"brightbox_database_type": dataSourceBrightboxDatabaseType(),
-			"brightbox_server_group":  dataSourceBrightboxServerGroup(),
+			"brightbox_account":                   dataSourceBrightboxAccount(),
+			"brightbox_image":                     dataSourceBrightboxImage(),
+			"brightbox_database_type":             dataSourceBrightboxDatabaseType(),
+			"brightbox_server_group":              dataSourceBrightboxServerGroup(),
+			"brightbox_server_type":               dataSourceBrightboxServerType(),
+			"brightbox_server_effective_firewall": dataSourceBrightboxServerEffectiveFirewall(),
+			"brightbox_server_snapshots":          dataSourceBrightboxServerSnapshots(),
+			"brightbox_load_balancer_certificate": dataSourceBrightboxLoadBalancerCertificate(),
+			"brightbox_server_console":            dataSourceBrightboxServerConsole(),
+			"brightbox_cloud_config":              dataSourceBrightboxCloudConfig(),
+			"brightbox_zones":                     dataSourceBrightboxZones(),
+			"brightbox_zone":                      dataSourceBrightboxZone(),
+			"brightbox_server":                    dataSourceBrightboxServer(),
+			"brightbox_cloudip":                   dataSourceBrightboxCloudip(),
+			"brightbox_database_server":           dataSourceBrightboxDatabaseServer(),
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"brightbox_server":          resourceBrightboxServer(),
-			"brightbox_cloudip":         resourceBrightboxCloudip(),
-			"brightbox_server_group":    resourceBrightboxServerGroup(),
-			"brightbox_firewall_policy": resourceBrightboxFirewallPolicy(),
-			"brightbox_firewall_rule":   resourceBrightboxFirewallRule(),
-			"brightbox_load_balancer":   resourceBrightboxLoadBalancer(),
-			"brightbox_database_server": resourceBrightboxDatabaseServer(),
-			"brightbox_orbit_container": resourceBrightboxContainer(),
-			"brightbox_api_client":      resourceBrightboxApiClient(),
+			"brightbox_server":            resourceBrightboxServer(),
+			"brightbox_cloudip":           resourceBrightboxCloudip(),
+			"brightbox_server_group":      resourceBrightboxServerGroup(),
+			"brightbox_firewall_policy":   resourceBrightboxFirewallPolicy(),
+			"brightbox_firewall_rule":     resourceBrightboxFirewallRule(),
+			"brightbox_load_balancer":     resourceBrightboxLoadBalancer(),
+			"brightbox_database_server":   resourceBrightboxDatabaseServer(),
+			"brightbox_database_snapshot": resourceBrightboxDatabaseSnapshot(),
+			"brightbox_orbit_container":   resourceBrightboxContainer(),
+			"brightbox_orbit_object":      resourceBrightboxOrbitObject(),
+			"brightbox_api_client":        resourceBrightboxApiClient(),
+			"brightbox_server_snapshot":   resourceBrightboxServerSnapshot(),
 		},
 		ConfigureFunc: providerConfigure,
 	}
@@ -87,13 +253,51 @@ func Provider() terraform.ResourceProvider {
 
 func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 	config := &authdetails{
-		APIClient: d.Get("apiclient").(string),
-		APISecret: d.Get("apisecret").(string),
-		UserName:  d.Get("username").(string),
-		password:  d.Get("password").(string),
-		Account:   d.Get("account").(string),
-		APIURL:    d.Get("apiurl").(string),
-		OrbitUrl:  d.Get("orbit_url").(string),
+		APIClient:           d.Get("apiclient").(string),
+		APISecret:           d.Get("apisecret").(string),
+		UserName:            d.Get("username").(string),
+		password:            d.Get("password").(string),
+		otp:                 d.Get("otp").(string),
+		Account:             d.Get("account").(string),
+		APIURL:              d.Get("apiurl").(string),
+		OrbitUrl:            d.Get("orbit_url").(string),
+		MaxRequests:         d.Get("max_concurrent_requests").(int),
+		MaxRetries:          d.Get("max_retries").(int),
+		UserDataLimit:       d.Get("user_data_limit").(int),
+		DefaultMetadata:     map_from_string_map(d.Get("default_metadata").(map[string]interface{})),
+		AllowedServerGroups: map_from_string_set(d, "allowed_server_groups"),
+		NamePrefix:          d.Get("name_prefix").(string),
+		TokenCacheFile:      d.Get("token_cache_file").(string),
+	}
+
+	err := config.setRegionEndpoints(d.Get("region").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	config.DialTimeout, err = time.ParseDuration(d.Get("dial_timeout").(string))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid dial_timeout: %s", err)
+	}
+	config.KeepAlive, err = time.ParseDuration(d.Get("keepalive").(string))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid keepalive: %s", err)
+	}
+	config.HTTPTimeout, err = time.ParseDuration(d.Get("http_timeout").(string))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid http_timeout: %s", err)
+	}
+	if timeout := d.Get("default_create_timeout").(string); timeout != "" {
+		config.DefaultCreateTimeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid default_create_timeout: %s", err)
+		}
+	}
+	if timeout := d.Get("default_delete_timeout").(string); timeout != "" {
+		config.DefaultDeleteTimeout, err = time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid default_delete_timeout: %s", err)
+		}
 	}
 
 	if strings.HasPrefix(config.APIClient, appPrefix) {
@@ -108,7 +312,7 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		}
 	} else {
 		log.Printf("[DEBUG] Detected API Client.")
-		if config.UserName != "" || config.password != "" {
+		if config.UserName != "" || config.password != "" || config.otp != "" {
 			return nil,
 				fmt.Errorf("User Credentials should be blank with an API Client")
 		}
@@ -1,9 +1,14 @@
 package brightbox
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
+	brightbox "github.com/brightbox/gobrightbox"
+	"github.com/gophercloud/gophercloud"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
@@ -72,6 +77,15 @@ func TestProvider_badConfigs(t *testing.T) {
 			},
 			err: "User Credentials should be blank with an API Client",
 		},
+		{
+			name: "Apiclient with One Time Password",
+			raw: map[string]interface{}{
+				"apiclient": "cli-12345",
+				"apisecret": "mysecret",
+				"otp":       "123456",
+			},
+			err: "User Credentials should be blank with an API Client",
+		},
 		{
 			name: "Specific app id with missing user",
 			raw: map[string]interface{}{
@@ -126,6 +140,106 @@ func TestProvider_badConfigs(t *testing.T) {
 	}
 }
 
+func TestProvider_durations(t *testing.T) {
+	p := Provider()
+	_, errs := p.Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"dial_timeout":           "10s",
+		"keepalive":              "1m",
+		"http_timeout":           "0s",
+		"default_create_timeout": "30m",
+		"default_delete_timeout": "1h",
+	}))
+	if len(errs) > 0 {
+		t.Errorf("Unexpected errors for valid durations: %v", errs)
+	}
+	_, errs = p.Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"dial_timeout":           "10",
+		"keepalive":              "forever",
+		"http_timeout":           "60",
+		"default_create_timeout": "slow",
+	}))
+	if len(errs) != 4 {
+		t.Errorf("Expected 4 errors for invalid durations, got %v", errs)
+	}
+}
+
+func TestCompositeClient_timeout(t *testing.T) {
+	d := resourceBrightboxLoadBalancer().Data(nil)
+	client := &CompositeClient{}
+	if got := client.timeout(d, schema.TimeoutCreate); got != defaultTimeout {
+		t.Errorf("Expected the resource default without provider defaults, got %s", got)
+	}
+
+	client.DefaultCreateTimeout = 30 * time.Minute
+	if got := client.timeout(d, schema.TimeoutCreate); got != 30*time.Minute {
+		t.Errorf("Expected the provider create timeout, got %s", got)
+	}
+	if got := client.timeout(d, schema.TimeoutDelete); got != defaultTimeout {
+		t.Errorf("Expected the resource delete default, got %s", got)
+	}
+
+	// As if the configuration had a timeouts block
+	resource := resourceBrightboxLoadBalancer()
+	resource.Timeouts = &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(10 * time.Minute),
+	}
+	d = resource.Data(nil)
+	if got := client.timeout(d, schema.TimeoutCreate); got != 10*time.Minute {
+		t.Errorf("Expected the resource's own timeout, got %s", got)
+	}
+}
+
+func TestProvider_regionEndpoints(t *testing.T) {
+	var regionTests = []struct {
+		name     string
+		config   authdetails
+		region   string
+		apiURL   string
+		orbitURL string
+	}{
+		{
+			name:     "Default region",
+			region:   defaultRegion,
+			apiURL:   "https://api.gb1.brightbox.com/",
+			orbitURL: "https://orbit.brightbox.com/v1/",
+		},
+		{
+			name:     "Staging region",
+			region:   "gb1s",
+			apiURL:   "https://api.gb1s.brightbox.com/",
+			orbitURL: "https://orbit.gb1s.brightbox.com/v1/",
+		},
+		{
+			name:     "Explicit URL overrides region",
+			config:   authdetails{APIURL: "https://api.example.com/"},
+			region:   "gb1s",
+			apiURL:   "https://api.example.com/",
+			orbitURL: "https://orbit.gb1s.brightbox.com/v1/",
+		},
+	}
+	for _, example := range regionTests {
+		t.Run(
+			example.name,
+			func(t *testing.T) {
+				config := example.config
+				if err := config.setRegionEndpoints(example.region); err != nil {
+					t.Fatalf("err: %s", err)
+				}
+				if config.APIURL != example.apiURL {
+					t.Errorf("Got api url %q, expected %q", config.APIURL, example.apiURL)
+				}
+				if config.OrbitUrl != example.orbitURL {
+					t.Errorf("Got orbit url %q, expected %q", config.OrbitUrl, example.orbitURL)
+				}
+			},
+		)
+	}
+	config := authdetails{}
+	if err := config.setRegionEndpoints("gb2"); err == nil {
+		t.Errorf("Expected an error for an unknown region")
+	}
+}
+
 func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = Provider()
 }
@@ -143,3 +257,22 @@ func testAccPreCheck(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// A client whose API and Orbit requests are answered by handler, for
+// unit tests. The caller closes the returned server.
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*CompositeClient, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	client, err := brightbox.NewClient(server.URL, "acc-12345", nil)
+	if err != nil {
+		server.Close()
+		t.Fatalf("err: %s", err)
+	}
+	return &CompositeClient{
+		ApiClient: client,
+		OrbitClient: &gophercloud.ServiceClient{
+			ProviderClient: &gophercloud.ProviderClient{HTTPClient: *http.DefaultClient},
+			Endpoint:       server.URL + "/",
+		},
+	}, server
+}
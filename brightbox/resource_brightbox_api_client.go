@@ -17,6 +17,9 @@ func resourceBrightboxApiClient() *schema.Resource {
 		Read:   resourceBrightboxApiClientRead,
 		Update: resourceBrightboxApiClientUpdate,
 		Delete: resourceBrightboxApiClientDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
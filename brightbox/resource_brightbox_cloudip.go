@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"net"
+	"regexp"
 	"strings"
 	"time"
 
@@ -22,6 +24,19 @@ const (
 	checkDelay         = 10 * time.Second
 )
 
+// Cloud IPs have no metadata, so ownership is recorded as a tag on the
+// end of the name
+var managedNameRe = regexp.MustCompile(`^(.*?) ?\[managed-by:([^\]]+)\]$`)
+
+// Cloud IPs can be mapped to servers, interfaces, load balancers,
+// database servers and server groups. An empty target leaves the Cloud
+// IP unmapped, as when it is set from a variable that may be blank.
+var cloudipTargetRe = regexp.MustCompile("^((srv|int|lba|dbs|grp)-[0-9a-z]{5})?$")
+
+// A fully qualified hostname, optionally ending in a dot. An empty
+// reverse_dns restores the default entry.
+var reverseDnsRe = regexp.MustCompile(`^$|^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.?$`)
+
 func resourceBrightboxCloudip() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceBrightboxCloudipCreate,
@@ -31,9 +46,11 @@ func resourceBrightboxCloudip() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceBrightboxCloudipCustomizeDiff,
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
 			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
@@ -46,6 +63,15 @@ func resourceBrightboxCloudip() *schema.Resource {
 			"target": {
 				Type:     schema.TypeString,
 				Optional: true,
+				ValidateFunc: validation.StringMatch(
+					cloudipTargetRe,
+					"must be empty or the id of a server, interface, load balancer, database server or server group",
+				),
+			},
+
+			"managed_by": {
+				Type:     schema.TypeString,
+				Optional: true,
 			},
 
 			"status": {
@@ -63,15 +89,35 @@ func resourceBrightboxCloudip() *schema.Resource {
 				Computed: true,
 			},
 
+			"public_ipv6": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
 			"fqdn": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
 
 			"reverse_dns": {
-				Type:     schema.TypeString,
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"auto_reverse_dns"},
+				ValidateFunc:     validation.StringMatch(reverseDnsRe, "must be a hostname"),
+				DiffSuppressFunc: suppressDefaultReverseDns,
+			},
+
+			"auto_reverse_dns": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"reverse_dns"},
+			},
+
+			"wait_for_reverse_dns": {
+				Type:     schema.TypeBool,
 				Optional: true,
-				Computed: true,
+				Default:  false,
 			},
 			"port_translator": {
 				Type:     schema.TypeSet,
@@ -123,7 +169,21 @@ func resourceBrightboxCloudipCreate(
 	d.SetId(cloudip.Id)
 
 	if target_id, ok := d.GetOk("target"); ok {
-		cloudip, err = assignCloudIP(client, cloudip.Id, target_id.(string), d.Timeout(schema.TimeoutCreate))
+		cloudip, err = assignCloudIP(client, cloudip.Id, target_id.(string), meta.(*CompositeClient).timeout(d, schema.TimeoutCreate))
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Get("auto_reverse_dns").(bool) {
+		cloudip, err = applyAutoReverseDns(client, cloudip)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Get("wait_for_reverse_dns").(bool) {
+		err = waitForReverseDns(cloudip, meta.(*CompositeClient).timeout(d, schema.TimeoutCreate))
 		if err != nil {
 			return err
 		}
@@ -156,7 +216,7 @@ func resourceBrightboxCloudipDelete(
 	meta interface{},
 ) error {
 	client := meta.(*CompositeClient).ApiClient
-	return removeCloudIP(client, d.Id(), d.Timeout(schema.TimeoutDelete))
+	return removeCloudIP(client, d.Id(), meta.(*CompositeClient).timeout(d, schema.TimeoutDelete))
 }
 
 func resourceBrightboxCloudipUpdate(
@@ -168,16 +228,16 @@ func resourceBrightboxCloudipUpdate(
 	d.Partial(true)
 
 	if d.HasChange("target") {
-		err := unmapCloudIP(client, d.Id(), d.Timeout(schema.TimeoutDelete))
+		old_target, new_target := d.GetChange("target")
+		var err error
+		if new_target.(string) == "" {
+			err = unmapCloudIP(client, d.Id(), meta.(*CompositeClient).timeout(d, schema.TimeoutDelete))
+		} else {
+			err = remapCloudIP(client, d.Id(), old_target.(string), new_target.(string), d.Timeout(schema.TimeoutUpdate))
+		}
 		if err != nil {
 			return err
 		}
-		if target_id, ok := d.GetOk("target"); ok {
-			_, err := assignCloudIP(client, d.Id(), target_id.(string), d.Timeout(schema.TimeoutCreate))
-			if err != nil {
-				return err
-			}
-		}
 		d.SetPartial("target")
 	}
 
@@ -195,6 +255,31 @@ func resourceBrightboxCloudipUpdate(
 		return fmt.Errorf("Error updating Cloud IP (%s): %s", cloudip_opts.Id, err)
 	}
 
+	// An empty list is left out of the update, so clear the last
+	// translator separately
+	if d.HasChange("port_translator") && len(cloudip_opts.PortTranslators) == 0 {
+		cloudip, err = clearPortTranslators(client, d.Id())
+		if err != nil {
+			return err
+		}
+	}
+
+	reverse_dns_changed := d.HasChange("reverse_dns")
+	if d.Get("auto_reverse_dns").(bool) && (d.HasChange("target") || d.HasChange("auto_reverse_dns")) {
+		cloudip, err = applyAutoReverseDns(client, cloudip)
+		if err != nil {
+			return err
+		}
+		reverse_dns_changed = true
+	}
+
+	if d.Get("wait_for_reverse_dns").(bool) && (reverse_dns_changed || d.HasChange("wait_for_reverse_dns")) {
+		err = waitForReverseDns(cloudip, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			return err
+		}
+	}
+
 	return setCloudipAttributes(d, cloudip)
 }
 
@@ -204,8 +289,12 @@ func assignCloudIP(
 	target_id string,
 	timeout time.Duration,
 ) (*brightbox.CloudIP, error) {
+	err := checkCloudipTarget(client, target_id)
+	if err != nil {
+		return nil, err
+	}
 	log.Printf("[INFO] Assigning Cloud IP %s to target %s", cloudip_id, target_id)
-	err := client.MapCloudIP(cloudip_id, target_id)
+	err = client.MapCloudIP(cloudip_id, target_id)
 	if err != nil {
 		return nil, fmt.Errorf("Error assigning Cloud IP %s to target %s: %s", cloudip_id, target_id, err)
 	}
@@ -216,6 +305,67 @@ func assignCloudIP(
 	return cloudip, err
 }
 
+// Move a Cloud IP to a new target. The API only maps unmapped Cloud IPs,
+// so this takes separate unmap and map requests, and the address does
+// not route anywhere in between. The new target is checked before the
+// Cloud IP is unmapped, and the previous mapping is restored if the new
+// one fails, so a failed move usually leaves the address where it was.
+func remapCloudIP(
+	client *brightbox.Client,
+	cloudip_id string,
+	old_target string,
+	new_target string,
+	timeout time.Duration,
+) error {
+	err := checkCloudipTarget(client, new_target)
+	if err != nil {
+		return err
+	}
+	err = unmapCloudIP(client, cloudip_id, timeout)
+	if err != nil {
+		return err
+	}
+	_, err = assignCloudIP(client, cloudip_id, new_target, timeout)
+	if err == nil || old_target == "" {
+		return err
+	}
+	log.Printf("[WARN] Remapping Cloud IP %s failed, restoring mapping to %s", cloudip_id, old_target)
+	if _, restore_err := assignCloudIP(client, cloudip_id, old_target, timeout); restore_err != nil {
+		return fmt.Errorf("%s (restoring the mapping to %s also failed: %s)", err, old_target, restore_err)
+	}
+	return fmt.Errorf("%s (the Cloud IP has been mapped back to %s)", err, old_target)
+}
+
+// Look up the target before mapping so that a reference to a missing
+// resource gets a clear error rather than a failed mapping request.
+func checkCloudipTarget(client *brightbox.Client, target_id string) error {
+	log.Printf("[DEBUG] Checking Cloud IP target %s exists", target_id)
+	var err error
+	switch {
+	case strings.HasPrefix(target_id, "srv-"):
+		var server *brightbox.Server
+		server, err = client.Server(target_id)
+		if err == nil && server.Status == "deleted" {
+			return fmt.Errorf("Cloud IP target server %s has been deleted", target_id)
+		}
+	case strings.HasPrefix(target_id, "int-"):
+		_, err = client.MakeApiRequest("GET", "/1.0/interfaces/"+target_id, nil, nil)
+	case strings.HasPrefix(target_id, "lba-"):
+		_, err = client.LoadBalancer(target_id)
+	case strings.HasPrefix(target_id, "dbs-"):
+		_, err = client.DatabaseServer(target_id)
+	case strings.HasPrefix(target_id, "grp-"):
+		_, err = client.ServerGroup(target_id)
+	}
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "missing_resource:") {
+			return fmt.Errorf("Cloud IP target %s does not exist", target_id)
+		}
+		return fmt.Errorf("Error retrieving Cloud IP target %s: %s", target_id, err)
+	}
+	return nil
+}
+
 func unmapCloudIP(
 	client *brightbox.Client,
 	cloudip_id string,
@@ -296,28 +446,26 @@ func setCloudipAttributes(
 	d *schema.ResourceData,
 	cloudip *brightbox.CloudIP,
 ) error {
-	d.Set("name", cloudip.Name)
+	name, managed_by := splitManagedName(cloudip.Name)
+	d.Set("name", name)
+	d.Set("managed_by", managed_by)
 	d.Set("public_ip", cloudip.PublicIP)
+	d.Set("public_ipv6", cloudip.PublicIPv6)
 	d.Set("status", cloudip.Status)
 	d.Set("locked", cloudip.Locked)
 	d.Set("reverse_dns", cloudip.ReverseDns)
 	d.Set("fqdn", cloudip.Fqdn)
-	// Set the server id first and let interface override it
-	// Server and interface should appear together, but catch at least one
-	if cloudip.Server != nil {
-		d.Set("target", cloudip.Server.Id)
-	}
-	if cloudip.Interface != nil {
-		d.Set("target", cloudip.Interface.Id)
+	// An unmapped (reserved) Cloud IP has no target. Only clear a
+	// previously recorded one, so a target removed outside Terraform
+	// shows as a diff without adding an empty attribute to every state.
+	target := cloudipTarget(cloudip)
+	// A server id is mapped to the server's interface; keep recording
+	// the server id if that is what was asked for.
+	if cloudip.Server != nil && d.Get("target").(string) == cloudip.Server.Id {
+		target = cloudip.Server.Id
 	}
-	if cloudip.LoadBalancer != nil {
-		d.Set("target", cloudip.LoadBalancer.Id)
-	}
-	if cloudip.DatabaseServer != nil {
-		d.Set("target", cloudip.DatabaseServer.Id)
-	}
-	if cloudip.ServerGroup != nil {
-		d.Set("target", cloudip.ServerGroup.Id)
+	if target != "" || d.Get("target").(string) != "" {
+		d.Set("target", target)
 	}
 	log.Printf("[DEBUG] PortTranslator details are %#v", cloudip.PortTranslators)
 	portTranslators := make([]map[string]interface{}, len(cloudip.PortTranslators))
@@ -333,6 +481,107 @@ func setCloudipAttributes(
 	return nil
 }
 
+// Return the id the Cloud IP is mapped to, or the empty string if it is
+// unmapped. Server and interface should appear together, but catch at
+// least one and let the interface override the server.
+func cloudipTarget(cloudip *brightbox.CloudIP) string {
+	target := ""
+	if cloudip.Server != nil {
+		target = cloudip.Server.Id
+	}
+	if cloudip.Interface != nil {
+		target = cloudip.Interface.Id
+	}
+	if cloudip.LoadBalancer != nil {
+		target = cloudip.LoadBalancer.Id
+	}
+	if cloudip.DatabaseServer != nil {
+		target = cloudip.DatabaseServer.Id
+	}
+	if cloudip.ServerGroup != nil {
+		target = cloudip.ServerGroup.Id
+	}
+	return target
+}
+
+// The reverse DNS a Cloud IP should have when it follows its mapping:
+// the public hostname of the server it is mapped to, or the Cloud IP's
+// own FQDN otherwise. The server's own FQDN resolves to its private
+// address, so only public.<fqdn> resolves back to the Cloud IP.
+func autoReverseDns(client *brightbox.Client, cloudip *brightbox.CloudIP) (string, error) {
+	if cloudip.Server == nil {
+		return cloudip.Fqdn, nil
+	}
+	server, err := client.Server(cloudip.Server.Id)
+	if err != nil {
+		return "", fmt.Errorf("Error retrieving server %s for the reverse DNS of Cloud IP %s: %s",
+			cloudip.Server.Id, cloudip.Id, err)
+	}
+	return "public." + server.Fqdn, nil
+}
+
+func applyAutoReverseDns(client *brightbox.Client, cloudip *brightbox.CloudIP) (*brightbox.CloudIP, error) {
+	reverse_dns, err := autoReverseDns(client, cloudip)
+	if err != nil {
+		return nil, err
+	}
+	if reverse_dns == cloudip.ReverseDns {
+		return cloudip, nil
+	}
+	log.Printf("[INFO] Setting reverse DNS of Cloud IP %s to %s", cloudip.Id, reverse_dns)
+	cloudip, err = client.UpdateCloudIP(&brightbox.CloudIPOptions{
+		Id:         cloudip.Id,
+		ReverseDns: &reverse_dns,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error setting reverse DNS of Cloud IP %s: %s", cloudip.Id, err)
+	}
+	return cloudip, nil
+}
+
+// Resolver used to check reverse DNS, replaced in tests
+var lookupAddr = net.LookupAddr
+
+// Wait until the PTR record of the Cloud IP resolves to its reverse DNS
+// name. Changes take a while to reach the public DNS, and mail servers
+// should not start sending before they have.
+func waitForReverseDns(cloudip *brightbox.CloudIP, timeout time.Duration) error {
+	if cloudip.ReverseDns == "" {
+		return nil
+	}
+	log.Printf("[INFO] Waiting for %s to resolve to %s", cloudip.PublicIP, cloudip.ReverseDns)
+	stateConf := resource.StateChangeConf{
+		Pending:    []string{"pending"},
+		Target:     []string{"resolved"},
+		Refresh:    reverseDnsStateRefresh(cloudip.PublicIP, cloudip.ReverseDns),
+		Timeout:    timeout,
+		MinTimeout: minimumRefreshWait,
+	}
+	_, err := stateConf.WaitForState()
+	if err != nil {
+		return fmt.Errorf("Error waiting for the reverse DNS of Cloud IP %s: %s", cloudip.Id, err)
+	}
+	return nil
+}
+
+func reverseDnsStateRefresh(address string, reverse_dns string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		names, err := lookupAddr(address)
+		if err != nil {
+			log.Printf("[DEBUG] PTR lookup for %s failed: %s", address, err)
+			return address, "pending", nil
+		}
+		// DNS names are case insensitive
+		for _, name := range names {
+			if strings.EqualFold(strings.TrimSuffix(name, "."), strings.TrimSuffix(reverse_dns, ".")) {
+				return address, "resolved", nil
+			}
+		}
+		log.Printf("[DEBUG] %s resolves to %v, not yet %s", address, names, reverse_dns)
+		return address, "pending", nil
+	}
+}
+
 func removeCloudIP(client *brightbox.Client, id string, timeout time.Duration) error {
 	log.Printf("[DEBUG] Unmapping Cloud IP %s", id)
 	err := unmapCloudIP(client, id, timeout)
@@ -351,12 +600,55 @@ func addUpdateableCloudipOptions(
 	d *schema.ResourceData,
 	opts *brightbox.CloudIPOptions,
 ) error {
-	assign_string(d, &opts.Name, "name")
-	assign_string(d, &opts.ReverseDns, "reverse_dns")
+	assign_managed_name(d, &opts.Name)
+	if d.HasChange("reverse_dns") {
+		reverse_dns := d.Get("reverse_dns").(string)
+		if reverse_dns == "" {
+			// The default entry is the Cloud IP's own fqdn
+			reverse_dns = d.Get("fqdn").(string)
+		}
+		opts.ReverseDns = &reverse_dns
+	}
 	assign_port_translators(d, &opts.PortTranslators)
 	return nil
 }
 
+// Leaving reverse_dns unset accepts the default entry, or the one
+// auto_reverse_dns manages, without showing a diff.
+func suppressDefaultReverseDns(k, old, new string, d *schema.ResourceData) bool {
+	if new != "" {
+		return false
+	}
+	return old == d.Get("fqdn").(string) || d.Get("auto_reverse_dns").(bool)
+}
+
+func assign_managed_name(d *schema.ResourceData, target **string) {
+	if d.IsNewResource() || d.HasChange("name") || d.HasChange("managed_by") {
+		managed_by := d.Get("managed_by").(string)
+		temp := managedName(d.Get("name").(string), managed_by)
+		*target = &temp
+	}
+}
+
+func managedName(name string, managed_by string) string {
+	if managed_by == "" {
+		return name
+	}
+	tag := "[managed-by:" + managed_by + "]"
+	if name == "" {
+		return tag
+	}
+	return name + " " + tag
+}
+
+func splitManagedName(full_name string) (string, string) {
+	match := managedNameRe.FindStringSubmatch(full_name)
+	if match == nil {
+		return full_name, ""
+	}
+	return match[1], match[2]
+}
+
 func resourceBrightboxPortTranslationHash(
 	v interface{},
 ) int {
@@ -370,6 +662,42 @@ func resourceBrightboxPortTranslationHash(
 	return hashcode.String(buf.String())
 }
 
+func resourceBrightboxCloudipCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("port_translator") {
+		return nil
+	}
+	return checkDuplicatePortTranslators(d.Get("port_translator").(*schema.Set).List())
+}
+
+// Traffic arriving on a port can only be translated one way, so each
+// incoming port and protocol pair may appear only once.
+func checkDuplicatePortTranslators(configured []interface{}) error {
+	seen := make(map[string]bool, len(configured))
+	for _, port_translator := range expandPortTranslators(configured) {
+		key := fmt.Sprintf("%d/%s", port_translator.Incoming, strings.ToLower(port_translator.Protocol))
+		if seen[key] {
+			return fmt.Errorf("port_translator has more than one translator for incoming port %s", key)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func clearPortTranslators(client *brightbox.Client, cloudip_id string) (*brightbox.CloudIP, error) {
+	log.Printf("[INFO] Removing port translators from Cloud IP %s", cloudip_id)
+	cloudip := new(brightbox.CloudIP)
+	_, err := client.MakeApiRequest(
+		"PUT",
+		"/1.0/cloud_ips/"+cloudip_id,
+		map[string][]brightbox.PortTranslator{"port_translators": {}},
+		cloudip,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Error removing port translators from Cloud IP (%s): %s", cloudip_id, err)
+	}
+	return cloudip, nil
+}
+
 func assign_port_translators(d *schema.ResourceData, target *[]brightbox.PortTranslator) {
 	if d.HasChange("port_translator") {
 		*target = expandPortTranslators(d.Get("port_translator").(*schema.Set).List())
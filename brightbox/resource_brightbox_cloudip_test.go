@@ -1,12 +1,17 @@
 package brightbox
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -30,14 +35,128 @@ func TestAccBrightboxCloudip_Basic(t *testing.T) {
 					testAccCheckBrightboxCloudipExists(resourceName, &cloudip),
 					resource.TestCheckResourceAttr(
 						resourceName, "name", fmt.Sprintf("foo-%d", rInt)),
+					resource.TestCheckResourceAttr(
+						resourceName, "managed_by", ""),
 					resource.TestCheckNoResourceAttr(
 						resourceName, "target"),
+					resource.TestCheckResourceAttr(
+						resourceName, "status", unmapped),
+				),
+			},
+			{
+				Config:   testAccCheckBrightboxCloudipConfig_basic(rInt),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccCheckBrightboxCloudipConfig_managed(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxCloudipExists(resourceName, &cloudip),
+					resource.TestCheckResourceAttr(
+						resourceName, "name", fmt.Sprintf("foo-%d", rInt)),
+					resource.TestCheckResourceAttr(
+						resourceName, "managed_by", "stack-1"),
+					testAccCheckBrightboxCloudipName(
+						&cloudip, fmt.Sprintf("foo-%d [managed-by:stack-1]", rInt)),
+				),
+			},
+			{
+				Config: testAccCheckBrightboxCloudipConfig_basic(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxCloudipExists(resourceName, &cloudip),
+					resource.TestCheckResourceAttr(
+						resourceName, "managed_by", ""),
+					testAccCheckBrightboxCloudipName(
+						&cloudip, fmt.Sprintf("foo-%d", rInt)),
 				),
 			},
 		},
 	})
 }
 
+func testAccCheckBrightboxCloudipName(cloudip *brightbox.CloudIP, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if cloudip.Name != name {
+			return fmt.Errorf("Bad Cloud IP name: %q, expected %q", cloudip.Name, name)
+		}
+		return nil
+	}
+}
+
+func TestCloudipManagedByDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "cip-12345",
+		Attributes: map[string]string{
+			"id":         "cip-12345",
+			"name":       "foo",
+			"managed_by": "stack-1",
+		},
+	}
+	config := map[string]interface{}{"name": "foo"}
+	diff, err := resourceBrightboxCloudip().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Attributes["managed_by"] == nil || diff.Attributes["managed_by"].New != "" {
+		t.Fatalf("Expected removing managed_by to clear the tag, got %v", diff)
+	}
+	d, err := schema.InternalMap(resourceBrightboxCloudip().Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	opts := &brightbox.CloudIPOptions{}
+	assign_managed_name(d, &opts.Name)
+	if opts.Name == nil || *opts.Name != "foo" {
+		t.Errorf("Expected the untagged name to be sent, got %v", opts.Name)
+	}
+}
+
+func TestSetCloudipAttributes_unmapped(t *testing.T) {
+	d := resourceBrightboxCloudip().Data(nil)
+	cloudip := &brightbox.CloudIP{
+		Id:        "cip-12345",
+		Status:    mapped,
+		Interface: &brightbox.ServerInterface{Id: "int-12345"},
+	}
+	setCloudipAttributes(d, cloudip)
+	if got := d.Get("target").(string); got != "int-12345" {
+		t.Errorf("Expected target int-12345, got %q", got)
+	}
+	cloudip.Status = unmapped
+	cloudip.Interface = nil
+	setCloudipAttributes(d, cloudip)
+	if got := d.Get("target").(string); got != "" {
+		t.Errorf("Expected an unmapped Cloud IP to clear the target, got %q", got)
+	}
+	if got := d.Get("status").(string); got != unmapped {
+		t.Errorf("Expected status %q, got %q", unmapped, got)
+	}
+}
+
+func TestManagedName(t *testing.T) {
+	var nameTests = []struct {
+		name      string
+		managedBy string
+		fullName  string
+	}{
+		{"foo", "", "foo"},
+		{"foo", "terraform", "foo [managed-by:terraform]"},
+		{"", "terraform", "[managed-by:terraform]"},
+		{"foo [bar]", "stack-1", "foo [bar] [managed-by:stack-1]"},
+		{"", "", ""},
+	}
+	for _, example := range nameTests {
+		fullName := managedName(example.name, example.managedBy)
+		if fullName != example.fullName {
+			t.Errorf("Got name %q, expected %q", fullName, example.fullName)
+		}
+		name, managedBy := splitManagedName(fullName)
+		if name != example.name || managedBy != example.managedBy {
+			t.Errorf("Split %q into %q and %q, expected %q and %q",
+				fullName, name, managedBy, example.name, example.managedBy)
+		}
+	}
+}
+
 func TestAccBrightboxCloudip_clear_name(t *testing.T) {
 	var cloudip brightbox.CloudIP
 	rInt := acctest.RandInt()
@@ -102,6 +221,267 @@ func TestAccBrightboxCloudip_Mapped(t *testing.T) {
 	})
 }
 
+func TestAccBrightboxCloudip_ServerTarget(t *testing.T) {
+	var cloudip brightbox.CloudIP
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxCloudipDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxCloudipConfig_server_target(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxCloudipExists(resourceName, &cloudip),
+					resource.TestCheckResourceAttrPair(
+						resourceName, "target", "brightbox_server.boofar", "id"),
+					resource.TestCheckResourceAttr(
+						resourceName, "status", mapped),
+				),
+			},
+			{
+				Config:   testAccCheckBrightboxCloudipConfig_server_target(rInt),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccBrightboxCloudip_AutoReverseDns(t *testing.T) {
+	var cloudip brightbox.CloudIP
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxCloudipDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxCloudipConfig_auto_reverse_dns(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxCloudipExists(resourceName, &cloudip),
+					testAccCheckBrightboxCloudipServerReverseDns(
+						resourceName, "brightbox_server.boofar"),
+				),
+			},
+			{
+				Config:   testAccCheckBrightboxCloudipConfig_auto_reverse_dns(rInt),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxCloudipServerReverseDns(n string, server string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[server]
+		if !ok {
+			return fmt.Errorf("Not found: %s", server)
+		}
+		return resource.TestCheckResourceAttr(
+			n, "reverse_dns", "public."+rs.Primary.Attributes["fqdn"])(s)
+	}
+}
+
+func TestAutoReverseDns(t *testing.T) {
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.0/servers/srv-12345" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"srv-12345","fqdn":"srv-12345.gb1.brightbox.com"}`))
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	cloudip := &brightbox.CloudIP{
+		Id:     "cip-12345",
+		Fqdn:   "cip-109-107-35-1.gb1.brightbox.com",
+		Server: &brightbox.Server{Id: "srv-12345"},
+	}
+	if got, err := autoReverseDns(client, cloudip); err != nil || got != "public.srv-12345.gb1.brightbox.com" {
+		t.Errorf("Got %q (%v), expected the server's public hostname", got, err)
+	}
+	cloudip.Server = nil
+	if got, err := autoReverseDns(client, cloudip); err != nil || got != cloudip.Fqdn {
+		t.Errorf("Got %q (%v), expected the Cloud IP's own FQDN when not mapped to a server", got, err)
+	}
+}
+
+func TestWaitForReverseDns(t *testing.T) {
+	defer func(original func(string) ([]string, error)) { lookupAddr = original }(lookupAddr)
+	lookups := 0
+	lookupAddr = func(address string) ([]string, error) {
+		lookups++
+		if address != "109.107.35.1" {
+			return nil, fmt.Errorf("unexpected lookup of %s", address)
+		}
+		if lookups < 2 {
+			return []string{"cip-109-107-35-1.gb1.brightbox.com."}, nil
+		}
+		return []string{"Mail.Example.com."}, nil
+	}
+
+	cloudip := &brightbox.CloudIP{
+		Id:         "cip-12345",
+		PublicIP:   "109.107.35.1",
+		ReverseDns: "mail.example.com",
+	}
+	if err := waitForReverseDns(cloudip, time.Minute); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if lookups != 2 {
+		t.Errorf("Expected to wait for the PTR record to change, got %d lookups", lookups)
+	}
+
+	cloudip.ReverseDns = "smtp.example.com"
+	if err := waitForReverseDns(cloudip, 10*time.Millisecond); err == nil {
+		t.Errorf("Expected a timeout waiting for a PTR record that never resolves")
+	}
+
+	lookups = 0
+	cloudip.ReverseDns = ""
+	if err := waitForReverseDns(cloudip, time.Minute); err != nil || lookups != 0 {
+		t.Errorf("Expected no wait without reverse DNS, got %v after %d lookups", err, lookups)
+	}
+}
+
+func TestAccBrightboxCloudip_MissingTarget(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxCloudipDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccCheckBrightboxCloudipConfig_missing_target(rInt),
+				ExpectError: regexp.MustCompile("Cloud IP target srv-00000 does not exist"),
+			},
+		},
+	})
+}
+
+func TestCloudipTargetValidation(t *testing.T) {
+	validate := resourceBrightboxCloudip().Schema["target"].ValidateFunc
+	for _, target := range []string{"", "srv-12345", "int-abcde", "lba-12345", "dbs-12345", "grp-12345"} {
+		if _, errs := validate(target, "target"); len(errs) != 0 {
+			t.Errorf("Expected %q to be a valid target, got %v", target, errs)
+		}
+	}
+	for _, target := range []string{"cip-12345", "srv-123", "web server"} {
+		if _, errs := validate(target, "target"); len(errs) == 0 {
+			t.Errorf("Expected %q to be rejected as a target", target)
+		}
+	}
+}
+
+func TestCloudipReverseDnsValidation(t *testing.T) {
+	validate := resourceBrightboxCloudip().Schema["reverse_dns"].ValidateFunc
+	for _, name := range []string{"", "mail.example.com", "mx-1.example.co.uk."} {
+		if _, errs := validate(name, "reverse_dns"); len(errs) != 0 {
+			t.Errorf("Expected %q to be a valid reverse DNS entry, got %v", name, errs)
+		}
+	}
+	for _, name := range []string{"mail", "mail server.example.com", "-mail.example.com", "109.107.1.1"} {
+		if _, errs := validate(name, "reverse_dns"); len(errs) == 0 {
+			t.Errorf("Expected %q to be rejected as a reverse DNS entry", name)
+		}
+	}
+}
+
+func TestAddUpdateableCloudipOptions_clearReverseDns(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "cip-12345",
+		Attributes: map[string]string{
+			"id":          "cip-12345",
+			"fqdn":        "cip-109-107-1-1.gb1.brightbox.com",
+			"reverse_dns": "mail.example.com",
+		},
+	}
+	diff, err := resourceBrightboxCloudip().Diff(state, terraform.NewResourceConfigRaw(map[string]interface{}{}), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.RequiresNew() {
+		t.Fatalf("Expected clearing reverse_dns to update the Cloud IP in place, got %v", diff)
+	}
+	d, err := schema.InternalMap(resourceBrightboxCloudip().Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	opts := &brightbox.CloudIPOptions{}
+	addUpdateableCloudipOptions(d, opts)
+	if opts.ReverseDns == nil || *opts.ReverseDns != "cip-109-107-1-1.gb1.brightbox.com" {
+		t.Errorf("Expected the default reverse DNS to be restored, got %v", opts.ReverseDns)
+	}
+
+	state.Attributes["reverse_dns"] = "cip-109-107-1-1.gb1.brightbox.com"
+	diff, err = resourceBrightboxCloudip().Diff(state, terraform.NewResourceConfigRaw(map[string]interface{}{}), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := diff.Attributes["reverse_dns"]; ok {
+		t.Errorf("Expected the default reverse DNS to need no change, got %v", diff.Attributes["reverse_dns"])
+	}
+}
+
+func TestCheckDuplicatePortTranslators(t *testing.T) {
+	translator := func(incoming int, protocol string, outgoing int) interface{} {
+		return map[string]interface{}{"incoming": incoming, "protocol": protocol, "outgoing": outgoing}
+	}
+	valid := []interface{}{translator(80, "tcp", 8080), translator(80, "udp", 8080), translator(443, "tcp", 8443)}
+	if err := checkDuplicatePortTranslators(valid); err != nil {
+		t.Errorf("Expected distinct translators to be accepted, got %s", err)
+	}
+	duplicate := []interface{}{translator(80, "tcp", 8080), translator(80, "tcp", 8081)}
+	if err := checkDuplicatePortTranslators(duplicate); err == nil {
+		t.Errorf("Expected a repeated incoming port and protocol to be rejected")
+	}
+}
+
+func TestClearPortTranslators(t *testing.T) {
+	var body map[string]interface{}
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/1.0/cloud_ips/cip-12345" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"cip-12345","port_translators":[]}`))
+	})
+	defer server.Close()
+	client := meta.ApiClient
+	cloudip, err := clearPortTranslators(client, "cip-12345")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if cloudip.Id != "cip-12345" {
+		t.Errorf("Got Cloud IP %q", cloudip.Id)
+	}
+	if translators, ok := body["port_translators"].([]interface{}); !ok || len(translators) != 0 {
+		t.Errorf("Expected an empty list of port translators to be sent, got %v", body)
+	}
+}
+
+func TestSetCloudipAttributes_serverTarget(t *testing.T) {
+	d := resourceBrightboxCloudip().Data(nil)
+	d.Set("target", "srv-12345")
+	cloudip := &brightbox.CloudIP{
+		Id:        "cip-12345",
+		Status:    mapped,
+		Server:    &brightbox.Server{Id: "srv-12345"},
+		Interface: &brightbox.ServerInterface{Id: "int-12345"},
+	}
+	setCloudipAttributes(d, cloudip)
+	if got := d.Get("target").(string); got != "srv-12345" {
+		t.Errorf("Expected the server id to be kept as the target, got %q", got)
+	}
+}
+
 func TestAccBrightboxCloudip_Remapped(t *testing.T) {
 	var cloudip brightbox.CloudIP
 	rInt := acctest.RandInt()
@@ -133,6 +513,10 @@ func TestAccBrightboxCloudip_Remapped(t *testing.T) {
 					testAccCheckBrightboxCloudipExists(resourceName, &cloudip),
 					resource.TestCheckResourceAttr(
 						resourceName, "name", fmt.Sprintf("baz-%d", rInt)),
+					resource.TestCheckResourceAttrPair(
+						resourceName, "target", "brightbox_server.fred", "interface"),
+					resource.TestCheckResourceAttr(
+						resourceName, "status", mapped),
 				),
 			},
 			{
@@ -211,6 +595,15 @@ resource "brightbox_cloudip" "foobar" {
 `, rInt)
 }
 
+func testAccCheckBrightboxCloudipConfig_managed(rInt int) string {
+	return fmt.Sprintf(`
+resource "brightbox_cloudip" "foobar" {
+	name = "foo-%d"
+	managed_by = "stack-1"
+}
+`, rInt)
+}
+
 const testAccCheckBrightboxCloudipConfig_empty_name = `
 
 resource "brightbox_cloudip" "foobar" {
@@ -235,6 +628,50 @@ resource "brightbox_server" "boofar" {
 		TestAccBrightboxDataServerGroupConfig_default)
 }
 
+func testAccCheckBrightboxCloudipConfig_server_target(rInt int) string {
+	return fmt.Sprintf(`
+
+resource "brightbox_cloudip" "foobar" {
+	name = "bar-%d"
+	target = "${brightbox_server.boofar.id}"
+}
+
+resource "brightbox_server" "boofar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "bar-%d"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+}
+%s%s`, rInt, rInt, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
+
+func testAccCheckBrightboxCloudipConfig_auto_reverse_dns(rInt int) string {
+	return fmt.Sprintf(`
+
+resource "brightbox_cloudip" "foobar" {
+	name = "bar-%d"
+	target = "${brightbox_server.boofar.id}"
+	auto_reverse_dns = true
+}
+
+resource "brightbox_server" "boofar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "bar-%d"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+}
+%s%s`, rInt, rInt, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
+
+func testAccCheckBrightboxCloudipConfig_missing_target(rInt int) string {
+	return fmt.Sprintf(`
+resource "brightbox_cloudip" "foobar" {
+	name = "bar-%d"
+	target = "srv-00000"
+}
+`, rInt)
+}
+
 func testAccCheckBrightboxCloudipConfig_port_mapped(rInt int) string {
 	return fmt.Sprintf(`
 
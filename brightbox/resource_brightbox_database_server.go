@@ -3,13 +3,19 @@ package brightbox
 import (
 	"fmt"
 	"log"
+	"net"
+	"regexp"
+	"strings"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
+var loadBalancerIdRe = regexp.MustCompile("^lba-[0-9a-z]{5}$")
+
 var blank_database_server_opts = brightbox.DatabaseServerOptions{}
 
 func resourceBrightboxDatabaseServer() *schema.Resource {
@@ -21,6 +27,7 @@ func resourceBrightboxDatabaseServer() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceBrightboxDatabaseServerCustomizeDiff,
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(defaultTimeout),
@@ -29,22 +36,29 @@ func resourceBrightboxDatabaseServer() *schema.Resource {
 
 		Schema: map[string]*schema.Schema{
 			"name": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressGeneratedName,
+			},
+			"generated_name": {
 				Type:     schema.TypeString,
-				Optional: true,
+				Computed: true,
 			},
 			"description": {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
 			"maintenance_weekday": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Computed: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(0, 6),
 			},
 			"maintenance_hour": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Computed: true,
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(0, 23),
 			},
 			"database_engine": {
 				Type:     schema.TypeString,
@@ -65,16 +79,20 @@ func resourceBrightboxDatabaseServer() *schema.Resource {
 				ForceNew: true,
 			},
 			"allow_access": {
-				Type:     schema.TypeSet,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type: schema.TypeSet,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateAccessSource,
+				},
 				Required: true,
 				MinItems: 1,
 				Set:      schema.HashString,
 			},
 			"snapshot": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(databaseSnapshotIdRe, "must be a valid database snapshot ID"),
 			},
 			"snapshots_schedule": {
 				Type:         schema.TypeString,
@@ -102,6 +120,10 @@ func resourceBrightboxDatabaseServer() *schema.Resource {
 				Computed:  true,
 				Sensitive: true,
 			},
+			"reset_admin_password": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
 			"status": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -146,6 +168,22 @@ func setDatabaseServerAttributes(
 	d.SetPartial("zone")
 }
 
+// Database servers can be reached from addresses, CIDR blocks, servers,
+// server groups and load balancers
+func validateAccessSource(v interface{}, name string) (warns []string, errors []error) {
+	value := v.(string)
+	switch {
+	case serverIdRe.MatchString(value), serverGroupIdRe.MatchString(value), loadBalancerIdRe.MatchString(value):
+	case strings.Contains(value, "/"):
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			errors = append(errors, fmt.Errorf("%q: %q is not a valid CIDR block", name, value))
+		}
+	case net.ParseIP(value) == nil:
+		errors = append(errors, fmt.Errorf("%q: %q must be an IP address, CIDR block, server, server group or load balancer ID", name, value))
+	}
+	return
+}
+
 func setAllowAccessAttribute(
 	d *schema.ResourceData,
 	database_server *brightbox.DatabaseServer,
@@ -170,7 +208,7 @@ func resourceBrightboxDatabaseServerCreate(
 	meta interface{},
 ) error {
 	client := meta.(*CompositeClient).ApiClient
-	err := createDatabaseServer(d, client)
+	err := createDatabaseServer(d, meta.(*CompositeClient))
 	if err != nil {
 		return err
 	}
@@ -179,13 +217,15 @@ func resourceBrightboxDatabaseServerCreate(
 	return updateDatabaseServerAttributes(d, client, database_server_opts)
 }
 
-func createDatabaseServer(d *schema.ResourceData, client *brightbox.Client) error {
+func createDatabaseServer(d *schema.ResourceData, meta *CompositeClient) error {
+	client := meta.ApiClient
 	log.Printf("[DEBUG] Database Server create called")
 	database_server_opts := getBlankDatabaseServerOpts()
 	err := addUpdateableDatabaseServerOptions(d, database_server_opts)
 	if err != nil {
 		return err
 	}
+	assignGeneratedName(d, &database_server_opts.Name, meta.NamePrefix)
 	engine := &database_server_opts.Engine
 	assign_string(d, &engine, "database_engine")
 	version := &database_server_opts.Version
@@ -194,6 +234,12 @@ func createDatabaseServer(d *schema.ResourceData, client *brightbox.Client) erro
 	assign_string(d, &databaseType, "database_type")
 	snapshot := &database_server_opts.Snapshot
 	assign_string(d, &snapshot, "snapshot")
+	if database_server_opts.Snapshot != "" {
+		err := checkDatabaseSnapshot(client, database_server_opts)
+		if err != nil {
+			return err
+		}
+	}
 	zone := &database_server_opts.Zone
 	assign_string(d, &zone, "zone")
 	log.Printf("[DEBUG] Database Server create configuration %#v", database_server_opts)
@@ -205,6 +251,7 @@ func createDatabaseServer(d *schema.ResourceData, client *brightbox.Client) erro
 	log.Printf("[DEBUG] Setting Partial")
 	d.Partial(true)
 	d.SetId(database_server.Id)
+	d.SetPartial("generated_name")
 	if database_server.AdminPassword == "" {
 		log.Printf("[WARN] No password returned for Cloud SQL server %s", database_server.Id)
 	} else {
@@ -215,7 +262,7 @@ func createDatabaseServer(d *schema.ResourceData, client *brightbox.Client) erro
 		Pending:    []string{"creating"},
 		Target:     []string{"active"},
 		Refresh:    databaseServerStateRefresh(client, database_server.Id),
-		Timeout:    d.Timeout(schema.TimeoutCreate),
+		Timeout:    meta.timeout(d, schema.TimeoutCreate),
 		Delay:      checkDelay,
 		MinTimeout: minimumRefreshWait,
 	}
@@ -228,6 +275,34 @@ func createDatabaseServer(d *schema.ResourceData, client *brightbox.Client) erro
 	return nil
 }
 
+// Fail early with a clear message if the database server can't be
+// restored from the snapshot. Snapshots in another region can't be seen
+// at all.
+func checkDatabaseSnapshot(
+	client *brightbox.Client,
+	opts *brightbox.DatabaseServerOptions,
+) error {
+	snapshot, err := client.DatabaseSnapshot(opts.Snapshot)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "missing_resource:") {
+			return fmt.Errorf("Database Snapshot %s not found. Snapshots can only be restored within the region they were taken in", opts.Snapshot)
+		}
+		return fmt.Errorf("Error retrieving Database Snapshot details: %s", err)
+	}
+	if snapshot.Status != "available" {
+		return fmt.Errorf("Database Snapshot %s is %s, not available", snapshot.Id, snapshot.Status)
+	}
+	if opts.Engine != "" && opts.Engine != snapshot.DatabaseEngine {
+		return fmt.Errorf("Database Snapshot %s is of a %s database and can't be restored as %s",
+			snapshot.Id, snapshot.DatabaseEngine, opts.Engine)
+	}
+	if opts.Version != "" && opts.Version != snapshot.DatabaseVersion {
+		return fmt.Errorf("Database Snapshot %s is of version %s and can't be restored as version %s",
+			snapshot.Id, snapshot.DatabaseVersion, opts.Version)
+	}
+	return nil
+}
+
 func resourceBrightboxDatabaseServerUpdate(
 	d *schema.ResourceData,
 	meta interface{},
@@ -235,6 +310,12 @@ func resourceBrightboxDatabaseServerUpdate(
 	client := meta.(*CompositeClient).ApiClient
 	log.Printf("[DEBUG] Setting Partial")
 	d.Partial(true)
+	if d.HasChange("reset_admin_password") && d.Get("reset_admin_password").(string) != "" {
+		err := resetDatabaseServerPassword(d, client)
+		if err != nil {
+			return err
+		}
+	}
 	// Create/Update Database
 	database_server_opts := getBlankDatabaseServerOpts()
 	err := addUpdateableDatabaseServerOptions(d, database_server_opts)
@@ -242,11 +323,43 @@ func resourceBrightboxDatabaseServerUpdate(
 		return err
 	}
 	assign_string_set(d, &database_server_opts.AllowAccess, "allow_access")
+	if cmp.Equal(*database_server_opts, blank_database_server_opts) {
+		log.Printf("[DEBUG] No other Database Server changes for %s", d.Id())
+		d.Partial(false)
+		return resourceBrightboxDatabaseServerRead(d, meta)
+	}
 	log.Printf("[DEBUG] Database Server update configuration %#v", database_server_opts)
 	output_database_server_options(database_server_opts)
 	return updateDatabaseServerAttributes(d, client, database_server_opts)
 }
 
+// Changing reset_admin_password replaces the admin password, so plan it
+// as unknown to let anything using the password pick up the new one in
+// the same apply.
+func resourceBrightboxDatabaseServerCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && d.HasChange("reset_admin_password") && d.Get("reset_admin_password").(string) != "" {
+		return d.SetNewComputed("admin_password")
+	}
+	return nil
+}
+
+// Ask the API for a new admin password. The old password stops working
+// straight away, and the new one is only returned by this call.
+func resetDatabaseServerPassword(d *schema.ResourceData, client *brightbox.Client) error {
+	log.Printf("[INFO] Resetting admin password for Database Server %s", d.Id())
+	database_server, err := client.ResetPasswordForDatabaseServer(d.Id())
+	if err != nil {
+		return fmt.Errorf("Error resetting Database Server admin password: %s", err)
+	}
+	if database_server.AdminPassword == "" {
+		return fmt.Errorf("No password returned when resetting admin password for Database Server %s", d.Id())
+	}
+	d.Set("admin_password", database_server.AdminPassword)
+	d.SetPartial("admin_password")
+	d.SetPartial("reset_admin_password")
+	return nil
+}
+
 func updateDatabaseServer(
 	client *brightbox.Client,
 	database_server_opts *brightbox.DatabaseServerOptions,
@@ -320,7 +433,7 @@ func resourceBrightboxDatabaseServerDelete(
 		Pending:    []string{"deleting", "active"},
 		Target:     []string{"deleted"},
 		Refresh:    databaseServerStateRefresh(client, d.Id()),
-		Timeout:    d.Timeout(schema.TimeoutDelete),
+		Timeout:    meta.(*CompositeClient).timeout(d, schema.TimeoutDelete),
 		Delay:      checkDelay,
 		MinTimeout: minimumRefreshWait,
 	}
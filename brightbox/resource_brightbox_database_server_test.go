@@ -143,6 +143,119 @@ func TestAccBrightboxDatabaseServer_BasicUpdates(t *testing.T) {
 	})
 }
 
+func TestDatabaseServerMaintenanceValidation(t *testing.T) {
+	database_server := resourceBrightboxDatabaseServer()
+	var rangeTests = []struct {
+		key   string
+		valid []int
+		bad   []int
+	}{
+		{"maintenance_weekday", []int{0, 6}, []int{-1, 7}},
+		{"maintenance_hour", []int{0, 23}, []int{-1, 24}},
+	}
+	for _, example := range rangeTests {
+		validate := database_server.Schema[example.key].ValidateFunc
+		for _, value := range example.valid {
+			if _, errs := validate(value, example.key); len(errs) != 0 {
+				t.Errorf("Expected %d to be a valid %s, got %v", value, example.key, errs)
+			}
+		}
+		for _, value := range example.bad {
+			if _, errs := validate(value, example.key); len(errs) == 0 {
+				t.Errorf("Expected %d to be rejected as a %s", value, example.key)
+			}
+		}
+	}
+}
+
+func TestValidateAccessSource(t *testing.T) {
+	for _, source := range []string{"srv-12345", "grp-12345", "lba-12345", "10.0.0.0/8", "192.168.1.10", "2a02:1348::/32"} {
+		if _, errs := validateAccessSource(source, "allow_access"); len(errs) != 0 {
+			t.Errorf("Expected %q to be a valid access source, got %v", source, errs)
+		}
+	}
+	for _, source := range []string{"", "srv-123", "grp_12345", "10.0.0.0/33", "10.0.0/8", "256.1.1.1", "db.example.com"} {
+		if _, errs := validateAccessSource(source, "allow_access"); len(errs) == 0 {
+			t.Errorf("Expected %q to be rejected as an access source", source)
+		}
+	}
+}
+
+func TestDatabaseServerCustomizeDiff_resetAdminPassword(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "dbs-12345",
+		Attributes: map[string]string{
+			"id":             "dbs-12345",
+			"admin_password": "old-password",
+		},
+	}
+	config := map[string]interface{}{"reset_admin_password": "1"}
+	diff, err := resourceBrightboxDatabaseServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Attributes["admin_password"] == nil || !diff.Attributes["admin_password"].NewComputed {
+		t.Errorf("Expected a password reset to plan a new admin_password, got %v", diff)
+	}
+
+	state.Attributes["reset_admin_password"] = "1"
+	diff, err = resourceBrightboxDatabaseServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil && diff.Attributes["admin_password"] != nil {
+		t.Errorf("Expected no new admin_password without a reset, got %#v", diff.Attributes["admin_password"])
+	}
+}
+
+func TestAccBrightboxDatabaseServer_ResetAdminPassword(t *testing.T) {
+	var database_server brightbox.DatabaseServer
+	var password string
+	name := fmt.Sprintf("bar-%d", acctest.RandInt())
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxDatabaseServerAndOthersDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDatabaseServerConfig_reset_password(name, "first"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxDatabaseServerExists("brightbox_database_server.default", &database_server),
+					testAccCheckBrightboxDatabaseServerPassword("brightbox_database_server.default", &password, false),
+				),
+			},
+			{
+				Config: testAccCheckBrightboxDatabaseServerConfig_reset_password(name, "second"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxDatabaseServerExists("brightbox_database_server.default", &database_server),
+					testAccCheckBrightboxDatabaseServerPassword("brightbox_database_server.default", &password, true),
+				),
+			},
+		},
+	})
+}
+
+// Record the admin password, checking it has changed from the last one
+// recorded if required.
+func testAccCheckBrightboxDatabaseServerPassword(n string, password *string, changed bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[n]
+		if !ok {
+			return fmt.Errorf("Not found: %s", n)
+		}
+		current := rs.Primary.Attributes["admin_password"]
+		if current == "" {
+			return fmt.Errorf("No admin password set")
+		}
+		if changed && current == *password {
+			return fmt.Errorf("Admin password has not been reset")
+		}
+		*password = current
+		return nil
+	}
+}
+
 func testAccCheckBrightboxDatabaseServerAndOthersDestroy(s *terraform.State) error {
 	err := testAccCheckBrightboxDatabaseServerDestroy(s)
 	if err != nil {
@@ -286,6 +399,20 @@ data "brightbox_database_type" "foobar" {
 `, name, name, TestAccBrightboxDataServerGroupConfig_default)
 }
 
+func testAccCheckBrightboxDatabaseServerConfig_reset_password(name string, trigger string) string {
+	return fmt.Sprintf(`
+
+resource "brightbox_database_server" "default" {
+	name = "%s"
+	database_engine = "mysql"
+	database_version = "8.0"
+	allow_access = [ "${data.brightbox_server_group.default.id}" ]
+	reset_admin_password = "%s"
+}
+%s
+`, name, trigger, TestAccBrightboxDataServerGroupConfig_default)
+}
+
 var testAccCheckBrightboxDatabaseServerConfig_clear_names = testAccCheckBrightboxDatabaseServerConfig_basic("")
 
 func testAccCheckBrightboxDatabaseServerConfig_update_maintenance(name string) string {
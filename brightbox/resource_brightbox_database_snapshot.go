@@ -0,0 +1,229 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+var databaseSnapshotIdRe = regexp.MustCompile("^dbi-[0-9a-z]{5}$")
+
+func resourceBrightboxDatabaseSnapshot() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceBrightboxDatabaseSnapshotCreate,
+		Read:   resourceBrightboxDatabaseSnapshotRead,
+		Update: resourceBrightboxDatabaseSnapshotUpdate,
+		Delete: resourceBrightboxDatabaseSnapshotDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"database_server_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(databaseServerIdRe, "must be a valid database server ID"),
+			},
+
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"database_engine": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"database_version": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"locked": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// The fields of a database snapshot that can be changed
+type databaseSnapshotOptions struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+func resourceBrightboxDatabaseSnapshotCreate(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	database_server_id := d.Get("database_server_id").(string)
+	log.Printf("[INFO] Snapshotting Database Server %s", database_server_id)
+	snapshot, err := client.SnapshotDatabaseServer(database_server_id)
+	if err != nil {
+		return fmt.Errorf("Error snapshotting Database Server %s: %s", database_server_id, err)
+	}
+	if snapshot == nil {
+		return fmt.Errorf("Error snapshotting Database Server %s: no snapshot returned", database_server_id)
+	}
+	d.SetId(snapshot.Id)
+
+	log.Printf("[INFO] Waiting for Database Snapshot (%s) to become available", d.Id())
+	stateConf := resource.StateChangeConf{
+		Pending:    []string{"creating"},
+		Target:     []string{"available"},
+		Refresh:    databaseSnapshotStateRefresh(client, d.Id()),
+		Timeout:    meta.(*CompositeClient).timeout(d, schema.TimeoutCreate),
+		Delay:      checkDelay,
+		MinTimeout: minimumRefreshWait,
+	}
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for snapshot %s of Database Server %s: %s", d.Id(), database_server_id, err)
+	}
+
+	snapshot_opts := &databaseSnapshotOptions{}
+	assign_string(d, &snapshot_opts.Name, "name")
+	assign_string(d, &snapshot_opts.Description, "description")
+	if snapshot_opts.Name != nil || snapshot_opts.Description != nil {
+		snapshot, err := updateDatabaseSnapshot(client, d.Id(), snapshot_opts)
+		if err != nil {
+			return err
+		}
+		return setDatabaseSnapshotAttributes(d, snapshot)
+	}
+
+	return resourceBrightboxDatabaseSnapshotRead(d, meta)
+}
+
+func resourceBrightboxDatabaseSnapshotRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	snapshot, err := client.DatabaseSnapshot(d.Id())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "missing_resource:") {
+			log.Printf("[WARN] Database Snapshot not found, removing from state: %s", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error retrieving Database Snapshot details: %s", err)
+	}
+	if snapshot.Status == "deleted" || snapshot.Status == "deleting" {
+		log.Printf("[WARN] Database Snapshot %s is %s, removing from state", d.Id(), snapshot.Status)
+		d.SetId("")
+		return nil
+	}
+
+	return setDatabaseSnapshotAttributes(d, snapshot)
+}
+
+func resourceBrightboxDatabaseSnapshotUpdate(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	snapshot_opts := &databaseSnapshotOptions{}
+	assign_string(d, &snapshot_opts.Name, "name")
+	assign_string(d, &snapshot_opts.Description, "description")
+	snapshot, err := updateDatabaseSnapshot(client, d.Id(), snapshot_opts)
+	if err != nil {
+		return err
+	}
+
+	return setDatabaseSnapshotAttributes(d, snapshot)
+}
+
+func resourceBrightboxDatabaseSnapshotDelete(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	log.Printf("[INFO] Deleting Database Snapshot %s", d.Id())
+	err := client.DestroyDatabaseSnapshot(d.Id())
+	if err != nil {
+		return fmt.Errorf("Error deleting Database Snapshot (%s): %s", d.Id(), err)
+	}
+	return nil
+}
+
+func databaseSnapshotStateRefresh(client *brightbox.Client, snapshotID string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		snapshot, err := client.DatabaseSnapshot(snapshotID)
+		if err != nil {
+			log.Printf("Error on Database Snapshot State Refresh: %s", err)
+			return nil, "", err
+		}
+		return snapshot, snapshot.Status, nil
+	}
+}
+
+// gobrightbox has no database snapshot update call, so make the request
+// directly.
+func updateDatabaseSnapshot(
+	client *brightbox.Client,
+	snapshot_id string,
+	opts *databaseSnapshotOptions,
+) (*brightbox.DatabaseSnapshot, error) {
+	log.Printf("[DEBUG] Database Snapshot update configuration: %#v", opts)
+	snapshot := new(brightbox.DatabaseSnapshot)
+	_, err := client.MakeApiRequest("PUT", "/1.0/database_snapshots/"+snapshot_id, opts, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("Error updating Database Snapshot (%s): %s", snapshot_id, err)
+	}
+	return snapshot, nil
+}
+
+func setDatabaseSnapshotAttributes(
+	d *schema.ResourceData,
+	snapshot *brightbox.DatabaseSnapshot,
+) error {
+	d.Set("name", snapshot.Name)
+	d.Set("description", snapshot.Description)
+	d.Set("status", snapshot.Status)
+	d.Set("size", snapshot.Size)
+	d.Set("database_engine", snapshot.DatabaseEngine)
+	d.Set("database_version", snapshot.DatabaseVersion)
+	d.Set("locked", snapshot.Locked)
+	if snapshot.CreatedAt != nil {
+		d.Set("created_at", snapshot.CreatedAt.Format(time.RFC3339))
+	}
+	return nil
+}
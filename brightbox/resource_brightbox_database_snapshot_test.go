@@ -0,0 +1,175 @@
+package brightbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccBrightboxDatabaseSnapshot_Basic(t *testing.T) {
+	rInt := acctest.RandInt()
+	name := fmt.Sprintf("foo-%d", rInt)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxDatabaseSnapshotDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxDatabaseSnapshotConfig_basic(name, "nightly"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"brightbox_database_snapshot.foobar", "status", "available"),
+					resource.TestCheckResourceAttr(
+						"brightbox_database_snapshot.foobar", "name", name+" nightly"),
+					resource.TestCheckResourceAttrPair(
+						"brightbox_database_snapshot.foobar", "database_version",
+						"brightbox_database_server.default", "database_version"),
+				),
+			},
+			{
+				Config: testAccCheckBrightboxDatabaseSnapshotConfig_basic(name, "weekly"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"brightbox_database_snapshot.foobar", "name", name+" weekly"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxDatabaseSnapshotDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*CompositeClient).ApiClient
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "brightbox_database_snapshot" {
+			continue
+		}
+
+		snapshot, err := client.DatabaseSnapshot(rs.Primary.ID)
+		if err != nil {
+			apierror := err.(brightbox.ApiError)
+			if apierror.StatusCode != 404 {
+				return fmt.Errorf(
+					"Error waiting for database snapshot %s to be destroyed: %s",
+					rs.Primary.ID, err)
+			}
+			continue
+		}
+		if snapshot.Status != "deleted" && snapshot.Status != "deleting" {
+			return fmt.Errorf("Database snapshot %s still exists", rs.Primary.ID)
+		}
+	}
+
+	return testAccCheckBrightboxDatabaseServerDestroy(s)
+}
+
+func TestResourceBrightboxDatabaseSnapshotRead(t *testing.T) {
+	status := "available"
+	var update map[string]string
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.0/database_snapshots/dbi-12345" {
+			http.NotFound(w, r)
+			return
+		}
+		name := "Snapshot of dbs-12345"
+		if r.Method == "PUT" {
+			json.NewDecoder(r.Body).Decode(&update)
+			name = update["name"]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"dbi-12345","name":%q,"status":%q,"database_engine":"mysql",
+			"database_version":"8.0","size":2048,"created_at":"2026-01-02T03:04:05Z"}`, name, status)
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	d := resourceBrightboxDatabaseSnapshot().Data(nil)
+	d.SetId("dbi-12345")
+	if err := resourceBrightboxDatabaseSnapshotRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("database_version").(string); got != "8.0" {
+		t.Errorf("Got database_version %q, expected 8.0", got)
+	}
+	if got := d.Get("size").(int); got != 2048 {
+		t.Errorf("Got size %d, expected 2048", got)
+	}
+	if got := d.Get("created_at").(string); got != "2026-01-02T03:04:05Z" {
+		t.Errorf("Got created_at %q", got)
+	}
+
+	name := "nightly"
+	snapshot, err := updateDatabaseSnapshot(client, "dbi-12345", &databaseSnapshotOptions{Name: &name})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if snapshot.Name != "nightly" || update["name"] != "nightly" {
+		t.Errorf("Expected the name to be sent, got %v", update)
+	}
+	if _, ok := update["description"]; ok {
+		t.Errorf("Expected an unchanged description to be left out, got %v", update)
+	}
+
+	status = "deleted"
+	if err := resourceBrightboxDatabaseSnapshotRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "" {
+		t.Errorf("Expected a deleted snapshot to be removed from state")
+	}
+}
+
+func TestCheckDatabaseSnapshot(t *testing.T) {
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1.0/database_snapshots/dbi-12345":
+			w.Write([]byte(`{"id":"dbi-12345","status":"available","database_engine":"mysql","database_version":"5.7"}`))
+		case "/1.0/database_snapshots/dbi-23456":
+			w.Write([]byte(`{"id":"dbi-23456","status":"creating","database_engine":"mysql","database_version":"5.7"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_name":"missing_resource","errors":["Resource not found"]}`))
+		}
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	var checkTests = []struct {
+		opts     brightbox.DatabaseServerOptions
+		expected string
+	}{
+		{brightbox.DatabaseServerOptions{Snapshot: "dbi-12345"}, ""},
+		{brightbox.DatabaseServerOptions{Snapshot: "dbi-12345", Engine: "mysql", Version: "5.7"}, ""},
+		{brightbox.DatabaseServerOptions{Snapshot: "dbi-12345", Version: "8.0"}, "version 5.7"},
+		{brightbox.DatabaseServerOptions{Snapshot: "dbi-12345", Engine: "postgresql"}, "mysql database"},
+		{brightbox.DatabaseServerOptions{Snapshot: "dbi-23456"}, "is creating"},
+		{brightbox.DatabaseServerOptions{Snapshot: "dbi-99999"}, "region"},
+	}
+	for _, example := range checkTests {
+		err := checkDatabaseSnapshot(client, &example.opts)
+		switch {
+		case example.expected == "" && err != nil:
+			t.Errorf("%#v: unexpected error %s", example.opts, err)
+		case example.expected != "" && (err == nil || !strings.Contains(err.Error(), example.expected)):
+			t.Errorf("%#v: expected an error mentioning %q, got %v", example.opts, example.expected, err)
+		}
+	}
+}
+
+func testAccCheckBrightboxDatabaseSnapshotConfig_basic(name string, schedule string) string {
+	return testAccCheckBrightboxDatabaseServerConfig_basic(name) + fmt.Sprintf(`
+resource "brightbox_database_snapshot" "foobar" {
+	database_server_id = "${brightbox_database_server.default.id}"
+	name = "%s %s"
+}
+`, name, schedule)
+}
@@ -16,7 +16,7 @@ func resourceBrightboxFirewallPolicy() *schema.Resource {
 		Update: resourceBrightboxFirewallPolicyUpdate,
 		Delete: resourceBrightboxFirewallPolicyDelete,
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: resourceBrightboxFirewallPolicyImport,
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -87,6 +87,13 @@ func resourceBrightboxFirewallPolicyDelete(
 ) error {
 	client := meta.(*CompositeClient).ApiClient
 
+	if server_group_id := d.Get("server_group").(string); server_group_id != "" {
+		_, err := updateFirewallPolicyServerGroup(client, d.Id(), server_group_id, "")
+		if err != nil {
+			return err
+		}
+	}
+
 	log.Printf("[INFO] Deleting Firewall Policy %s", d.Id())
 	err := client.DestroyFirewallPolicy(d.Id())
 	if err != nil {
@@ -108,6 +115,8 @@ func resourceBrightboxFirewallPolicyUpdate(
 	if err != nil {
 		return err
 	}
+	// The server group is changed by removing and applying the policy
+	firewall_policy_opts.ServerGroup = nil
 	log.Printf("[INFO] Firewall Policy update configuration: %#v", firewall_policy_opts)
 
 	firewall_policy, err := client.UpdateFirewallPolicy(firewall_policy_opts)
@@ -115,9 +124,75 @@ func resourceBrightboxFirewallPolicyUpdate(
 		return fmt.Errorf("Error updating Firewall Policy (%s): %s", firewall_policy_opts.Id, err)
 	}
 
+	if d.HasChange("server_group") {
+		old, new := d.GetChange("server_group")
+		moved, err := updateFirewallPolicyServerGroup(client, d.Id(), old.(string), new.(string))
+		if err != nil {
+			return err
+		}
+		if moved != nil {
+			firewall_policy = moved
+		}
+	}
+
 	return setFirewallPolicyAttributes(d, firewall_policy)
 }
 
+// A policy applies to one server group at a time, so it is removed from
+// the old group before being applied to the new one. Returns the policy
+// as of the last change made, if any.
+func updateFirewallPolicyServerGroup(
+	client *brightbox.Client,
+	firewall_policy_id string,
+	old string,
+	new string,
+) (*brightbox.FirewallPolicy, error) {
+	var firewall_policy *brightbox.FirewallPolicy
+	if old != "" {
+		log.Printf("[INFO] Removing Firewall Policy %s from Server Group %s", firewall_policy_id, old)
+		removed, err := client.RemoveFirewallPolicy(firewall_policy_id, old)
+		if err != nil {
+			return nil, fmt.Errorf("Error removing Firewall Policy (%s) from Server Group %s: %s", firewall_policy_id, old, err)
+		}
+		firewall_policy = removed
+	}
+	if new != "" {
+		log.Printf("[INFO] Applying Firewall Policy %s to Server Group %s", firewall_policy_id, new)
+		applied, err := client.ApplyFirewallPolicy(firewall_policy_id, new)
+		if err != nil {
+			return nil, fmt.Errorf("Error applying Firewall Policy (%s) to Server Group %s: %s", firewall_policy_id, new, err)
+		}
+		firewall_policy = applied
+	}
+	return firewall_policy, nil
+}
+
+// Import the policy along with each of its rules, so that an imported
+// policy is represented completely in state
+func resourceBrightboxFirewallPolicyImport(
+	d *schema.ResourceData,
+	meta interface{},
+) ([]*schema.ResourceData, error) {
+	client := meta.(*CompositeClient).ApiClient
+
+	firewall_policy, err := client.FirewallPolicy(d.Id())
+	if err != nil {
+		return nil, fmt.Errorf("Error retrieving Firewall Policy details: %s", err)
+	}
+
+	results := make([]*schema.ResourceData, 1, 1+len(firewall_policy.Rules))
+	results[0] = d
+	ruleResource := resourceBrightboxFirewallRule()
+	for _, rule := range firewall_policy.Rules {
+		log.Printf("[DEBUG] Importing Firewall Rule %s from Firewall Policy %s", rule.Id, d.Id())
+		ruleData := ruleResource.Data(nil)
+		ruleData.SetType("brightbox_firewall_rule")
+		ruleData.SetId(rule.Id)
+		results = append(results, ruleData)
+	}
+	return results, nil
+}
+
 func addUpdateableFirewallPolicyOptions(
 	d *schema.ResourceData,
 	opts *brightbox.FirewallPolicyOptions,
@@ -134,6 +209,10 @@ func setFirewallPolicyAttributes(
 ) error {
 	d.Set("name", firewall_policy.Name)
 	d.Set("description", firewall_policy.Description)
-	d.Set("server_group", firewall_policy.ServerGroup)
+	if firewall_policy.ServerGroup != nil {
+		d.Set("server_group", firewall_policy.ServerGroup.Id)
+	} else {
+		d.Set("server_group", "")
+	}
 	return nil
 }
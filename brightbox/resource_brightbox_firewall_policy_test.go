@@ -1,7 +1,11 @@
 package brightbox
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/brightbox/gobrightbox"
@@ -121,6 +125,53 @@ func TestAccBrightboxFirewallPolicy_mappings(t *testing.T) {
 	})
 }
 
+func TestUpdateFirewallPolicyServerGroup(t *testing.T) {
+	var requests []string
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, r.URL.Path+" "+body["server_group"])
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/apply_to") {
+			fmt.Fprintf(w, `{"id":"fwp-12345","server_group":{"id":%q}}`, body["server_group"])
+		} else {
+			w.Write([]byte(`{"id":"fwp-12345","server_group":null}`))
+		}
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	var bindingTests = []struct {
+		name     string
+		old      string
+		new      string
+		expected []string
+		group    string
+	}{
+		{"apply", "", "grp-aaaaa", []string{"/1.0/firewall_policies/fwp-12345/apply_to grp-aaaaa"}, "grp-aaaaa"},
+		{"move", "grp-aaaaa", "grp-bbbbb", []string{
+			"/1.0/firewall_policies/fwp-12345/remove grp-aaaaa",
+			"/1.0/firewall_policies/fwp-12345/apply_to grp-bbbbb",
+		}, "grp-bbbbb"},
+		{"remove", "grp-bbbbb", "", []string{"/1.0/firewall_policies/fwp-12345/remove grp-bbbbb"}, ""},
+	}
+	for _, example := range bindingTests {
+		requests = nil
+		firewall_policy, err := updateFirewallPolicyServerGroup(client, "fwp-12345", example.old, example.new)
+		if err != nil {
+			t.Fatalf("%s: err: %s", example.name, err)
+		}
+		if !reflect.DeepEqual(requests, example.expected) {
+			t.Errorf("%s: got requests %v, expected %v", example.name, requests, example.expected)
+		}
+		d := resourceBrightboxFirewallPolicy().Data(nil)
+		setFirewallPolicyAttributes(d, firewall_policy)
+		if got := d.Get("server_group").(string); got != example.group {
+			t.Errorf("%s: got server_group %q, expected %q", example.name, got, example.group)
+		}
+	}
+}
+
 func testAccCheckBrightboxFirewallPolicyAndGroupDestroy(s *terraform.State) error {
 	err := testAccCheckBrightboxFirewallPolicyDestroy(s)
 	if err != nil {
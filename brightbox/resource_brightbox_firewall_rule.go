@@ -3,9 +3,13 @@ package brightbox
 import (
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 )
 
@@ -18,6 +22,13 @@ func resourceBrightboxFirewallRule() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceBrightboxFirewallRuleCustomizeDiff,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
+			Delete: schema.DefaultTimeout(defaultTimeout),
+		},
 
 		Schema: map[string]*schema.Schema{
 			"firewall_policy": {
@@ -29,16 +40,18 @@ func resourceBrightboxFirewallRule() *schema.Resource {
 				Optional: true,
 			},
 			"source": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateFirewallRuleAddress,
 			},
 			"source_port": {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
 			"destination": {
-				Type:     schema.TypeString,
-				Optional: true,
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateFirewallRuleAddress,
 			},
 			"destination_port": {
 				Type:     schema.TypeString,
@@ -52,6 +65,11 @@ func resourceBrightboxFirewallRule() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"adopt_existing": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 		},
 	}
 }
@@ -73,9 +91,31 @@ func resourceBrightboxFirewallRuleCreate(
 
 	log.Printf("[INFO] Firewall Rule create configuration: %#v", firewall_rule_opts)
 
-	firewall_rule, err := client.CreateFirewallRule(firewall_rule_opts)
-	if err != nil {
-		return fmt.Errorf("Error creating Firewall Rule: %s", err)
+	defer meta.(*CompositeClient).lockFirewallPolicy(firewall_rule_opts.FirewallPolicy)()
+	// A create retried after a partial failure may find its rule was
+	// already added. When asked, adopt that rather than adding a
+	// duplicate. An identical rule may belong to someone else, so this
+	// is never done by default.
+	var firewall_rule *brightbox.FirewallRule
+	if d.Get("adopt_existing").(bool) {
+		firewall_policy, err := client.FirewallPolicy(firewall_rule_opts.FirewallPolicy)
+		if err != nil {
+			return fmt.Errorf("Error retrieving Firewall Policy %s: %s", firewall_rule_opts.FirewallPolicy, err)
+		}
+		firewall_rule = findEquivalentFirewallRule(firewall_policy.Rules, firewall_rule_opts)
+		if firewall_rule != nil {
+			log.Printf("[INFO] Adopting existing Firewall Rule %s", firewall_rule.Id)
+			firewall_rule.FirewallPolicy.Id = firewall_policy.Id
+		}
+	}
+	if firewall_rule == nil {
+		err = retryFirewallRuleConflict(meta.(*CompositeClient).timeout(d, schema.TimeoutCreate), func() (err error) {
+			firewall_rule, err = client.CreateFirewallRule(firewall_rule_opts)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Error creating Firewall Rule: %s", err)
+		}
 	}
 
 	d.SetId(firewall_rule.Id)
@@ -109,7 +149,10 @@ func resourceBrightboxFirewallRuleDelete(
 	client := meta.(*CompositeClient).ApiClient
 
 	log.Printf("[INFO] Deleting Firewall Rule %s", d.Id())
-	err := client.DestroyFirewallRule(d.Id())
+	defer meta.(*CompositeClient).lockFirewallPolicy(d.Get("firewall_policy").(string))()
+	err := retryFirewallRuleConflict(meta.(*CompositeClient).timeout(d, schema.TimeoutDelete), func() error {
+		return client.DestroyFirewallRule(d.Id())
+	})
 	if err != nil {
 		return fmt.Errorf("Error deleting Firewall Rule (%s): %s", d.Id(), err)
 	}
@@ -131,7 +174,12 @@ func resourceBrightboxFirewallRuleUpdate(
 	}
 	log.Printf("[DEBUG] Firewall Rule update configuration: %#v", firewall_rule_opts)
 
-	firewall_rule, err := client.UpdateFirewallRule(firewall_rule_opts)
+	defer meta.(*CompositeClient).lockFirewallPolicy(d.Get("firewall_policy").(string))()
+	var firewall_rule *brightbox.FirewallRule
+	err = retryFirewallRuleConflict(d.Timeout(schema.TimeoutUpdate), func() (err error) {
+		firewall_rule, err = client.UpdateFirewallRule(firewall_rule_opts)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error updating Firewall Rule (%s): %s", firewall_rule_opts.Id, err)
 	}
@@ -139,6 +187,91 @@ func resourceBrightboxFirewallRuleUpdate(
 	return setFirewallRuleAttributes(d, firewall_rule)
 }
 
+// ICMP has types rather than ports, so catch a rule mixing the two in
+// the plan rather than have the API refuse it
+func resourceBrightboxFirewallRuleCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("protocol") {
+		return nil
+	}
+	if d.Get("protocol").(string) == "icmp" {
+		for _, key := range []string{"source_port", "destination_port"} {
+			if d.NewValueKnown(key) && d.Get(key).(string) != "" {
+				return fmt.Errorf("%s must be empty when protocol is icmp, use icmp_type_name instead", key)
+			}
+		}
+	} else if d.NewValueKnown("icmp_type_name") && d.Get("icmp_type_name").(string) != "" {
+		return fmt.Errorf("icmp_type_name can only be used when protocol is icmp")
+	}
+	return nil
+}
+
+// Rules can match any address, an address or CIDR block, a server or
+// the servers of a server group
+func validateFirewallRuleAddress(v interface{}, name string) (warns []string, errors []error) {
+	value := v.(string)
+	switch {
+	case value == "any":
+	case strings.HasPrefix(value, "grp-"):
+		if !serverGroupIdRe.MatchString(value) {
+			errors = append(errors, fmt.Errorf("%q: %q is not a valid server group ID", name, value))
+		}
+	case strings.HasPrefix(value, "srv-"):
+		if !serverIdRe.MatchString(value) {
+			errors = append(errors, fmt.Errorf("%q: %q is not a valid server ID", name, value))
+		}
+	case strings.Contains(value, "/"):
+		if _, _, err := net.ParseCIDR(value); err != nil {
+			errors = append(errors, fmt.Errorf("%q: %q is not a valid CIDR block", name, value))
+		}
+	case net.ParseIP(value) == nil:
+		errors = append(errors, fmt.Errorf("%q: %q must be any, an IP address, CIDR block, server or server group ID", name, value))
+	}
+	return
+}
+
+// Rule changes are serialised per policy within this provider, but the
+// API can still reject a change with a conflict while another client
+// is changing the same policy. Retry those until they go through.
+func retryFirewallRuleConflict(timeout time.Duration, f func() error) error {
+	return resource.Retry(timeout, func() *resource.RetryError {
+		err := f()
+		if apierr, ok := err.(brightbox.ApiError); ok && apierr.StatusCode == http.StatusConflict {
+			log.Printf("[WARN] Firewall policy changed concurrently, retrying: %s", err)
+			return resource.RetryableError(err)
+		}
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		return nil
+	})
+}
+
+// Find a rule matching the options in every field, description
+// included, so that rules which differ only in intent are not merged.
+func findEquivalentFirewallRule(
+	rules []brightbox.FirewallRule,
+	opts *brightbox.FirewallRuleOptions,
+) *brightbox.FirewallRule {
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	for _, rule := range rules {
+		if rule.Protocol == value(opts.Protocol) &&
+			rule.Source == value(opts.Source) &&
+			rule.SourcePort == value(opts.SourcePort) &&
+			rule.Destination == value(opts.Destination) &&
+			rule.DestinationPort == value(opts.DestinationPort) &&
+			rule.IcmpTypeName == value(opts.IcmpTypeName) &&
+			rule.Description == value(opts.Description) {
+			return &rule
+		}
+	}
+	return nil
+}
+
 func addUpdateableFirewallRuleOptions(
 	d *schema.ResourceData,
 	opts *brightbox.FirewallRuleOptions,
@@ -2,11 +2,17 @@ package brightbox
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -46,6 +52,185 @@ func TestAccBrightboxFirewallRule_Basic(t *testing.T) {
 	})
 }
 
+// A fake API that answers with a conflict when a rule is changed while
+// another change is in progress.
+func testFirewallRuleConflictHandler(conflicts *int32) http.HandlerFunc {
+	var inFlight int32
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/1.0/firewall_rules/") {
+			http.NotFound(w, r)
+			return
+		}
+		if atomic.AddInt32(&inFlight, 1) > 1 {
+			atomic.AddInt32(&inFlight, -1)
+			atomic.AddInt32(conflicts, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_name":"conflict","errors":["Firewall policy is being changed"]}`))
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		id := strings.TrimPrefix(r.URL.Path, "/1.0/firewall_rules/")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":%q,"description":"updated","firewall_policy":{"id":"fwp-12345"}}`, id)
+	}
+}
+
+func TestFirewallRuleUpdate_concurrent(t *testing.T) {
+	var conflicts int32
+	meta, server := newTestClient(t, testFirewallRuleConflictHandler(&conflicts))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		d := schema.TestResourceDataRaw(t, resourceBrightboxFirewallRule().Schema, map[string]interface{}{
+			"firewall_policy": "fwp-12345",
+			"description":     "updated",
+		})
+		d.SetId(fmt.Sprintf("fwr-%05d", i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := resourceBrightboxFirewallRuleUpdate(d, meta); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if conflicts != 0 {
+		t.Errorf("Expected rule updates on one policy to be serialised, got %d conflicts", conflicts)
+	}
+}
+
+func TestRetryFirewallRuleConflict(t *testing.T) {
+	attempts := 0
+	err := retryFirewallRuleConflict(defaultTimeout, func() error {
+		attempts++
+		if attempts < 2 {
+			return brightbox.ApiError{StatusCode: http.StatusConflict}
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("Expected a conflict to be retried, got %v after %d attempts", err, attempts)
+	}
+
+	attempts = 0
+	err = retryFirewallRuleConflict(defaultTimeout, func() error {
+		attempts++
+		return brightbox.ApiError{StatusCode: http.StatusUnprocessableEntity}
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("Expected other errors to fail at once, got %v after %d attempts", err, attempts)
+	}
+}
+
+func TestFirewallRuleCreate_adoptExisting(t *testing.T) {
+	var creates int32
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/1.0/firewall_policies/fwp-12345":
+			w.Write([]byte(`{"id":"fwp-12345","rules":[
+				{"id":"fwr-aaaaa","protocol":"tcp","destination_port":"80","description":"http"},
+				{"id":"fwr-bbbbb","protocol":"tcp","destination_port":"443","description":"https"}
+			]}`))
+		case r.Method == "POST" && r.URL.Path == "/1.0/firewall_rules":
+			atomic.AddInt32(&creates, 1)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"fwr-ccccc","protocol":"tcp","destination_port":"22","description":"ssh","firewall_policy":{"id":"fwp-12345"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer server.Close()
+
+	var createTests = []struct {
+		port     string
+		desc     string
+		adopt    bool
+		expected string
+		creates  int32
+	}{
+		{"443", "https", true, "fwr-bbbbb", 0},
+		{"22", "ssh", true, "fwr-ccccc", 1},
+		{"443", "https", false, "fwr-ccccc", 2},
+	}
+	for _, example := range createTests {
+		d := schema.TestResourceDataRaw(t, resourceBrightboxFirewallRule().Schema, map[string]interface{}{
+			"firewall_policy":  "fwp-12345",
+			"protocol":         "tcp",
+			"destination_port": example.port,
+			"description":      example.desc,
+			"adopt_existing":   example.adopt,
+		})
+		if err := resourceBrightboxFirewallRuleCreate(d, meta); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if d.Id() != example.expected {
+			t.Errorf("Got rule %q, expected %q", d.Id(), example.expected)
+		}
+		if got := d.Get("firewall_policy").(string); got != "fwp-12345" {
+			t.Errorf("Got firewall_policy %q", got)
+		}
+		if creates != example.creates {
+			t.Errorf("Expected %d rules created, got %d", example.creates, creates)
+		}
+	}
+}
+
+func TestFirewallRuleCustomizeDiff_icmp(t *testing.T) {
+	var diffTests = []struct {
+		name     string
+		config   map[string]interface{}
+		expected string
+	}{
+		{"ping", map[string]interface{}{"protocol": "icmp", "icmp_type_name": "echo-request"}, ""},
+		{"any icmp", map[string]interface{}{"protocol": "icmp"}, ""},
+		{"tcp port", map[string]interface{}{"protocol": "tcp", "destination_port": "22"}, ""},
+		{"icmp port", map[string]interface{}{"protocol": "icmp", "destination_port": "22"}, "destination_port must be empty"},
+		{"icmp source port", map[string]interface{}{"protocol": "icmp", "source_port": "22"}, "source_port must be empty"},
+		{"tcp icmp type", map[string]interface{}{"protocol": "tcp", "icmp_type_name": "echo-request"}, "icmp_type_name"},
+		{"no protocol icmp type", map[string]interface{}{"icmp_type_name": "echo-request"}, "icmp_type_name"},
+	}
+	for _, example := range diffTests {
+		example.config["firewall_policy"] = "fwp-12345"
+		_, err := resourceBrightboxFirewallRule().Diff(nil, terraform.NewResourceConfigRaw(example.config), &CompositeClient{})
+		switch {
+		case example.expected == "" && err != nil:
+			t.Errorf("%s: unexpected error %s", example.name, err)
+		case example.expected != "" && (err == nil || !strings.Contains(err.Error(), example.expected)):
+			t.Errorf("%s: expected an error mentioning %q, got %v", example.name, example.expected, err)
+		}
+	}
+}
+
+func TestValidateFirewallRuleAddress(t *testing.T) {
+	for _, address := range []string{"any", "grp-12345", "srv-12345", "10.1.1.23/32", "10.1.1.23", "2a02:1348::/32"} {
+		if _, errs := validateFirewallRuleAddress(address, "source"); len(errs) != 0 {
+			t.Errorf("Expected %q to be a valid address, got %v", address, errs)
+		}
+	}
+	var badAddresses = []struct {
+		address  string
+		expected string
+	}{
+		{"grp-123", "server group ID"},
+		{"srv-ABCDE", "server ID"},
+		{"10.1.1.0/33", "CIDR block"},
+		{"", "must be any"},
+		{"anywhere", "must be any"},
+		{"10.1.1", "must be any"},
+	}
+	for _, example := range badAddresses {
+		_, errs := validateFirewallRuleAddress(example.address, "source")
+		if len(errs) == 0 || !strings.Contains(errs[0].Error(), example.expected) {
+			t.Errorf("Expected %q to be rejected mentioning %q, got %v", example.address, example.expected, errs)
+		}
+	}
+}
+
 func TestAccBrightboxFirewallRule_clear_names(t *testing.T) {
 	var firewall_rule brightbox.FirewallRule
 	rInt := acctest.RandInt()
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/hashcode"
@@ -22,21 +23,31 @@ func resourceBrightboxLoadBalancer() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceBrightboxLoadBalancerCustomizeDiff,
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
 			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
 		Schema: map[string]*schema.Schema{
 			"name": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressGeneratedName,
+			},
+			"generated_name": {
 				Type:     schema.TypeString,
-				Optional: true,
+				Computed: true,
 			},
 			"policy": {
 				Type:     schema.TypeString,
 				Optional: true,
 				Computed: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					"least-connections", "round-robin", "source-address",
+				}, false),
 			},
 			"certificate_pem": {
 				Type:      schema.TypeString,
@@ -46,6 +57,7 @@ func resourceBrightboxLoadBalancer() *schema.Resource {
 			"certificate_private_key": {
 				Type:      schema.TypeString,
 				Optional:  true,
+				Sensitive: true,
 				StateFunc: hash_string,
 			},
 			"sslv3": {
@@ -68,11 +80,23 @@ func resourceBrightboxLoadBalancer() *schema.Resource {
 				ValidateFunc: validation.IntAtLeast(0),
 			},
 			"nodes": {
-				Type:     schema.TypeSet,
-				Elem:     &schema.Schema{Type: schema.TypeString},
+				Type:          schema.TypeSet,
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Optional:      true,
+				Computed:      true,
+				Set:           schema.HashString,
+				ConflictsWith: []string{"node_server_group"},
+			},
+			"node_server_group": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ValidateFunc:  validation.StringMatch(serverGroupIdRe, "must be a valid server group ID"),
+				ConflictsWith: []string{"nodes"},
+			},
+			"wait_for_nodes_attached": {
+				Type:     schema.TypeBool,
 				Optional: true,
-				Computed: true,
-				Set:      schema.HashString,
+				Default:  false,
 			},
 			"listener": {
 				Type:     schema.TypeSet,
@@ -82,6 +106,9 @@ func resourceBrightboxLoadBalancer() *schema.Resource {
 						"protocol": {
 							Type:     schema.TypeString,
 							Required: true,
+							ValidateFunc: validation.StringInSlice([]string{
+								"tcp", "http", "https", "http+ws", "https+wss",
+							}, false),
 						},
 						"in": {
 							Type:         schema.TypeInt,
@@ -112,8 +139,9 @@ func resourceBrightboxLoadBalancer() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"type": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"tcp", "http"}, false),
 						},
 						"port": {
 							Type:         schema.TypeInt,
@@ -218,6 +246,11 @@ func setLoadBalancerAttributes(
 	log.Printf("[DEBUG] Certificate details are %#v", load_balancer.Certificate)
 	if load_balancer.Certificate == nil {
 		d.Set("sslv3", false)
+		// Only a hash of the certificate is kept in the state, so a
+		// replaced certificate can't be spotted. One that has gone
+		// altogether can, and is uploaded again on the next apply.
+		d.Set("certificate_pem", "")
+		d.Set("certificate_private_key", "")
 	} else {
 		d.Set("sslv3", load_balancer.Certificate.SslV3)
 	}
@@ -248,6 +281,7 @@ func resourceBrightboxLoadBalancerCreate(
 	if err != nil {
 		return err
 	}
+	assignGeneratedName(d, &load_balancer_opts.Name, meta.(*CompositeClient).NamePrefix)
 
 	log.Printf("[DEBUG] Load Balancer create configuration %#v", load_balancer_opts)
 	output_load_balancer_options(load_balancer_opts)
@@ -265,7 +299,7 @@ func resourceBrightboxLoadBalancerCreate(
 		Pending:    []string{"creating"},
 		Target:     []string{"active"},
 		Refresh:    loadBalancerStateRefresh(client, load_balancer.Id),
-		Timeout:    d.Timeout(schema.TimeoutCreate),
+		Timeout:    meta.(*CompositeClient).timeout(d, schema.TimeoutCreate),
 		Delay:      checkDelay,
 		MinTimeout: minimumRefreshWait,
 	}
@@ -274,6 +308,13 @@ func resourceBrightboxLoadBalancerCreate(
 		return err
 	}
 
+	if d.Get("wait_for_nodes_attached").(bool) {
+		active_load_balancer, err = waitForLoadBalancerNodes(d, client, meta.(*CompositeClient).timeout(d, schema.TimeoutCreate))
+		if err != nil {
+			return err
+		}
+	}
+
 	return setLoadBalancerAttributes(d, active_load_balancer.(*brightbox.LoadBalancer))
 }
 
@@ -321,9 +362,105 @@ func resourceBrightboxLoadBalancerUpdate(
 		return fmt.Errorf("Error updating load_balancer: %s", err)
 	}
 
+	if d.HasChange("nodes") && d.Get("wait_for_nodes_attached").(bool) {
+		attached_load_balancer, err := waitForLoadBalancerNodes(d, client, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			return err
+		}
+		load_balancer = attached_load_balancer.(*brightbox.LoadBalancer)
+	}
+
 	return setLoadBalancerAttributes(d, load_balancer)
 }
 
+// The API has no group based backends, so the nodes of a load balancer
+// following a server group are the group's members when planned. Each
+// plan brings the nodes back into line with the group.
+func resourceBrightboxLoadBalancerCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	group_id := d.Get("node_server_group").(string)
+	if !d.NewValueKnown("node_server_group") {
+		return d.SetNewComputed("nodes")
+	}
+	if group_id == "" {
+		return nil
+	}
+	client := meta.(*CompositeClient).ApiClient
+	log.Printf("[DEBUG] Reading members of server group %s for load balancer nodes", group_id)
+	group, err := client.ServerGroup(group_id)
+	if err != nil {
+		return fmt.Errorf("Error retrieving server group %s for load balancer nodes: %s", group_id, err)
+	}
+	members := serverGroupNodeIds(group.Servers)
+	current := d.Get("nodes").(*schema.Set)
+	if d.Id() != "" && current.Equal(schema.NewSet(schema.HashString, members)) {
+		return nil
+	}
+	return d.SetNew("nodes", members)
+}
+
+// The servers in a group that can act as load balancer nodes
+func serverGroupNodeIds(servers []brightbox.Server) []interface{} {
+	nodes := []interface{}{}
+	for _, server := range servers {
+		switch server.Status {
+		case "deleting", "deleted", "failed":
+			continue
+		}
+		nodes = append(nodes, server.Id)
+	}
+	return nodes
+}
+
+// Wait until the load balancer lists every node with an active server.
+// The API does not report the result of the healthcheck for each node,
+// so this says nothing about whether the nodes pass it.
+func waitForLoadBalancerNodes(
+	d *schema.ResourceData,
+	client *brightbox.Client,
+	timeout time.Duration,
+) (interface{}, error) {
+	nodes := d.Get("nodes").(*schema.Set)
+	log.Printf("[INFO] Waiting for nodes %v to be attached to Load Balancer (%s)", nodes.List(), d.Id())
+	stateConf := resource.StateChangeConf{
+		Pending:    []string{"waiting"},
+		Target:     []string{"attached"},
+		Refresh:    loadBalancerNodesStateRefresh(client, d.Id(), nodes),
+		Timeout:    timeout,
+		Delay:      checkDelay,
+		MinTimeout: minimumRefreshWait,
+	}
+	load_balancer, err := stateConf.WaitForState()
+	if err != nil {
+		return nil, fmt.Errorf("Error waiting for nodes to be attached to Load Balancer (%s): %s", d.Id(), err)
+	}
+	return load_balancer, nil
+}
+
+func loadBalancerNodesStateRefresh(client *brightbox.Client, loadBalancerID string, nodes *schema.Set) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		loadBalancer, err := client.LoadBalancer(loadBalancerID)
+		if err != nil {
+			log.Printf("Error on Load Balancer Nodes State Refresh: %s", err)
+			return nil, "", err
+		}
+		return loadBalancer, loadBalancerNodesState(loadBalancer, nodes), nil
+	}
+}
+
+func loadBalancerNodesState(loadBalancer *brightbox.LoadBalancer, nodes *schema.Set) string {
+	active := make(map[string]bool)
+	for _, node := range loadBalancer.Nodes {
+		active[node.Id] = node.Status == "active"
+	}
+	for _, node := range nodes.List() {
+		if !active[node.(string)] {
+			log.Printf("[DEBUG] Load Balancer %s node %s not attached", loadBalancer.Id, node)
+			return "waiting"
+		}
+	}
+	return "attached"
+}
+
 func resourceBrightboxLoadBalancerDelete(
 	d *schema.ResourceData,
 	meta interface{},
@@ -339,7 +476,7 @@ func resourceBrightboxLoadBalancerDelete(
 		Pending:    []string{"deleting", "active"},
 		Target:     []string{"deleted"},
 		Refresh:    loadBalancerStateRefresh(client, d.Id()),
-		Timeout:    d.Timeout(schema.TimeoutDelete),
+		Timeout:    meta.(*CompositeClient).timeout(d, schema.TimeoutDelete),
 		Delay:      checkDelay,
 		MinTimeout: minimumRefreshWait,
 	}
@@ -453,7 +590,7 @@ func output_load_balancer_options(opts *brightbox.LoadBalancerOptions) {
 		log.Printf("[DEBUG] Load Balancer CertificatePem %v", *opts.CertificatePem)
 	}
 	if opts.CertificatePrivateKey != nil {
-		log.Printf("[DEBUG] Load Balancer CertificatePrivateKey %v", *opts.CertificatePrivateKey)
+		log.Printf("[DEBUG] Load Balancer CertificatePrivateKey %v", hash_string(*opts.CertificatePrivateKey))
 	}
 	if opts.SslV3 != nil {
 		log.Printf("[DEBUG] Load Balancer SslV3 %v", *opts.SslV3)
@@ -2,10 +2,14 @@ package brightbox
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -102,6 +106,294 @@ func TestAccBrightboxLoadBalancer_BasicUpdates(t *testing.T) {
 	})
 }
 
+func TestAccBrightboxLoadBalancer_WaitForNodesAttached(t *testing.T) {
+	var load_balancer brightbox.LoadBalancer
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxLoadBalancerAndServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxLoadBalancerConfig_wait_for_nodes_attached,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxLoadBalancerExists("brightbox_load_balancer.default", &load_balancer),
+					testAccCheckBrightboxLoadBalancerNodesAttached(&load_balancer),
+					resource.TestCheckResourceAttr(
+						"brightbox_load_balancer.default", "wait_for_nodes_attached", "true"),
+					resource.TestCheckResourceAttr(
+						"brightbox_load_balancer.default", "nodes.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxLoadBalancerNodesAttached(load_balancer *brightbox.LoadBalancer) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if len(load_balancer.Nodes) != 1 {
+			return fmt.Errorf("Bad number of nodes: %d", len(load_balancer.Nodes))
+		}
+		if status := load_balancer.Nodes[0].Status; status != "active" {
+			return fmt.Errorf("Bad node status: %s", status)
+		}
+		return nil
+	}
+}
+
+func TestLoadBalancerNodesState(t *testing.T) {
+	load_balancer := &brightbox.LoadBalancer{
+		Id: "lba-12345",
+		Nodes: []brightbox.Server{
+			{Id: "srv-aaaaa", Status: "active"},
+			{Id: "srv-bbbbb", Status: "creating"},
+		},
+	}
+	var stateTests = []struct {
+		nodes    []interface{}
+		expected string
+	}{
+		{[]interface{}{}, "attached"},
+		{[]interface{}{"srv-aaaaa"}, "attached"},
+		{[]interface{}{"srv-aaaaa", "srv-bbbbb"}, "waiting"},
+		{[]interface{}{"srv-ccccc"}, "waiting"},
+	}
+	for _, example := range stateTests {
+		nodes := schema.NewSet(schema.HashString, example.nodes)
+		if got := loadBalancerNodesState(load_balancer, nodes); got != example.expected {
+			t.Errorf("Nodes %v: got %q, expected %q", example.nodes, got, example.expected)
+		}
+	}
+}
+
+func TestLoadBalancerHealthcheckValidation(t *testing.T) {
+	healthcheck := resourceBrightboxLoadBalancer().Schema["healthcheck"].Elem.(*schema.Resource)
+	validate := healthcheck.Schema["type"].ValidateFunc
+	for _, check_type := range []string{"tcp", "http"} {
+		if _, errs := validate(check_type, "type"); len(errs) != 0 {
+			t.Errorf("Expected %q to be a valid healthcheck type, got %v", check_type, errs)
+		}
+	}
+	for _, check_type := range []string{"", "https", "HTTP"} {
+		if _, errs := validate(check_type, "type"); len(errs) == 0 {
+			t.Errorf("Expected %q to be rejected as a healthcheck type", check_type)
+		}
+	}
+}
+
+func TestAssignHealthcheck_inPlace(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "lba-12345",
+		Attributes: map[string]string{
+			"id":                           "lba-12345",
+			"healthcheck.#":                "1",
+			"healthcheck.0.type":           "http",
+			"healthcheck.0.port":           "80",
+			"healthcheck.0.request":        "/",
+			"healthcheck.0.interval":       "5000",
+			"healthcheck.0.timeout":        "5000",
+			"healthcheck.0.threshold_up":   "3",
+			"healthcheck.0.threshold_down": "3",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"listener": []interface{}{
+			map[string]interface{}{"protocol": "http", "in": 80, "out": 8080},
+		},
+		"healthcheck": []interface{}{
+			map[string]interface{}{"type": "http", "port": 80, "request": "/status", "interval": 2000},
+		},
+	})
+	diff, err := resourceBrightboxLoadBalancer().Diff(state, config, &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Errorf("Expected a healthcheck change to update the load balancer in place")
+	}
+	d, err := schema.InternalMap(resourceBrightboxLoadBalancer().Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var healthcheck *brightbox.LoadBalancerHealthcheck
+	if err := assign_healthcheck(d, &healthcheck); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if healthcheck == nil {
+		t.Fatalf("Expected the changed healthcheck to be sent")
+	}
+	if healthcheck.Request != "/status" || healthcheck.Interval != 2000 || healthcheck.ThresholdUp != 3 {
+		t.Errorf("Got healthcheck %#v", *healthcheck)
+	}
+}
+
+func TestLoadBalancerPolicyValidation(t *testing.T) {
+	validate := resourceBrightboxLoadBalancer().Schema["policy"].ValidateFunc
+	for _, policy := range []string{"least-connections", "round-robin", "source-address"} {
+		if _, errs := validate(policy, "policy"); len(errs) != 0 {
+			t.Errorf("Expected %q to be a valid policy, got %v", policy, errs)
+		}
+	}
+	for _, policy := range []string{"", "source-ip", "cookie"} {
+		if _, errs := validate(policy, "policy"); len(errs) == 0 {
+			t.Errorf("Expected %q to be rejected as a policy", policy)
+		}
+	}
+}
+
+func TestLoadBalancerListenerProtocolValidation(t *testing.T) {
+	listener := resourceBrightboxLoadBalancer().Schema["listener"].Elem.(*schema.Resource)
+	validate := listener.Schema["protocol"].ValidateFunc
+	for _, protocol := range []string{"tcp", "http", "https", "http+ws", "https+wss"} {
+		if _, errs := validate(protocol, "protocol"); len(errs) != 0 {
+			t.Errorf("Expected %q to be a valid listener protocol, got %v", protocol, errs)
+		}
+	}
+	for _, protocol := range []string{"", "tls", "HTTPS"} {
+		if _, errs := validate(protocol, "protocol"); len(errs) == 0 {
+			t.Errorf("Expected %q to be rejected as a listener protocol", protocol)
+		}
+	}
+}
+
+func TestAddUpdateableLoadBalancerOptions_rotateCertificate(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "lba-12345",
+		Attributes: map[string]string{
+			"id":                      "lba-12345",
+			"certificate_pem":         hash_string("old certificate"),
+			"certificate_private_key": hash_string("old key"),
+			"listener.#":              "1",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"listener": []interface{}{
+			map[string]interface{}{"protocol": "https", "in": 443, "out": 8080},
+		},
+		"certificate_pem":         "new certificate",
+		"certificate_private_key": "new key",
+	})
+	diff, err := resourceBrightboxLoadBalancer().Diff(state, config, &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Errorf("Expected a new certificate to update the load balancer in place")
+	}
+	if attr := diff.Attributes["certificate_private_key"]; attr == nil || !attr.Sensitive {
+		t.Errorf("Expected the private key to be hidden in the plan")
+	}
+	d, err := schema.InternalMap(resourceBrightboxLoadBalancer().Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	opts := &brightbox.LoadBalancerOptions{}
+	if err := addUpdateableLoadBalancerOptions(d, opts); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if opts.CertificatePem == nil || *opts.CertificatePem != "new certificate" {
+		t.Errorf("Expected the new certificate to be sent, got %v", opts.CertificatePem)
+	}
+	if opts.CertificatePrivateKey == nil || *opts.CertificatePrivateKey != "new key" {
+		t.Errorf("Expected the new private key to be sent")
+	}
+}
+
+func TestSetLoadBalancerAttributes_certificateRemoved(t *testing.T) {
+	d := resourceBrightboxLoadBalancer().Data(nil)
+	d.Set("certificate_pem", "certificate")
+	d.Set("certificate_private_key", "key")
+	load_balancer := &brightbox.LoadBalancer{
+		Id:          "lba-12345",
+		Healthcheck: brightbox.LoadBalancerHealthcheck{Type: "tcp", Port: 443},
+		Certificate: &brightbox.LoadBalancerCertificate{SslV3: true},
+	}
+	setLoadBalancerAttributes(d, load_balancer)
+	if got := d.Get("certificate_pem").(string); got != "certificate" {
+		t.Errorf("Expected an installed certificate to be left alone, got %q", got)
+	}
+	if !d.Get("sslv3").(bool) {
+		t.Errorf("Expected sslv3 to be read from the certificate")
+	}
+
+	load_balancer.Certificate = nil
+	setLoadBalancerAttributes(d, load_balancer)
+	for _, key := range []string{"certificate_pem", "certificate_private_key"} {
+		if got := d.Get(key).(string); got != "" {
+			t.Errorf("Expected %s to be cleared once the certificate is gone, got %q", key, got)
+		}
+	}
+}
+
+func TestLoadBalancerCustomizeDiff_nodeServerGroup(t *testing.T) {
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.0/server_groups/grp-12345" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"grp-12345","servers":[
+			{"id":"srv-aaaaa","status":"active"},
+			{"id":"srv-bbbbb","status":"creating"},
+			{"id":"srv-ccccc","status":"deleting"}
+		]}`))
+	})
+	defer server.Close()
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"node_server_group": "grp-12345",
+		"listener": []interface{}{
+			map[string]interface{}{"protocol": "http", "in": 80, "out": 8080},
+		},
+	})
+	state := func(nodes ...string) *terraform.InstanceState {
+		attributes := map[string]string{
+			"id":                "lba-12345",
+			"node_server_group": "grp-12345",
+			"nodes.#":           strconv.Itoa(len(nodes)),
+		}
+		for _, node := range nodes {
+			attributes[fmt.Sprintf("nodes.%d", schema.HashString(node))] = node
+		}
+		return &terraform.InstanceState{ID: "lba-12345", Attributes: attributes}
+	}
+
+	changesNodes := func(diff *terraform.InstanceDiff) bool {
+		if diff == nil {
+			return false
+		}
+		for key := range diff.Attributes {
+			if strings.HasPrefix(key, "nodes.") {
+				return true
+			}
+		}
+		return false
+	}
+
+	diff, err := resourceBrightboxLoadBalancer().Diff(state("srv-aaaaa", "srv-ddddd"), config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !changesNodes(diff) {
+		t.Fatalf("Expected the nodes to follow the group")
+	}
+	d, err := schema.InternalMap(resourceBrightboxLoadBalancer().Schema).Data(state("srv-aaaaa", "srv-ddddd"), diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := schema.NewSet(schema.HashString, []interface{}{"srv-aaaaa", "srv-bbbbb"})
+	if got := d.Get("nodes").(*schema.Set); !got.Equal(expected) {
+		t.Errorf("Got nodes %v, expected %v", got.List(), expected.List())
+	}
+
+	diff, err = resourceBrightboxLoadBalancer().Diff(state("srv-bbbbb", "srv-aaaaa"), config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if changesNodes(diff) {
+		t.Errorf("Expected no change to nodes matching the group")
+	}
+}
+
 func testAccCheckBrightboxLoadBalancerAndServerDestroy(s *terraform.State) error {
 	err := testAccCheckBrightboxLoadBalancerDestroy(s)
 	if err != nil {
@@ -219,6 +511,33 @@ resource "brightbox_server" "foobar" {
 
 }
 
+%s%s`, TestAccBrightboxImageDataSourceConfig_blank_disk,
+	TestAccBrightboxDataServerGroupConfig_default)
+
+var testAccCheckBrightboxLoadBalancerConfig_wait_for_nodes_attached = fmt.Sprintf(`
+
+resource "brightbox_load_balancer" "default" {
+	name = "default"
+	listener {
+		protocol = "http"
+		in = 80
+		out = 8080
+	}
+	healthcheck {
+		type = "http"
+		port = 8080
+	}
+	nodes = ["${brightbox_server.foobar.id}"]
+	wait_for_nodes_attached = true
+}
+
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "load_balancer_test"
+	type = "1gb.ssd"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+}
+
 %s%s`, TestAccBrightboxImageDataSourceConfig_blank_disk,
 	TestAccBrightboxDataServerGroupConfig_default)
 
@@ -2,7 +2,9 @@ package brightbox
 
 import (
 	"log"
+	"net/http"
 	"net/url"
+	"reflect"
 	"strings"
 	"time"
 
@@ -25,6 +27,7 @@ func resourceBrightboxContainer() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceBrightboxContainerCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"name": {
@@ -41,6 +44,13 @@ func resourceBrightboxContainer() *schema.Resource {
 				},
 				ValidateFunc: http1Keys,
 			},
+			"metadata_all": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
 			"container_read": {
 				Type:     schema.TypeSet,
 				Optional: true,
@@ -91,6 +101,10 @@ func resourceBrightboxContainer() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+			"url": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -102,7 +116,7 @@ func resourceBrightboxContainerCreate(
 	client := meta.(*CompositeClient).OrbitClient
 
 	log.Printf("[INFO] Creating Container")
-	createOpts := getCreateContainerOptions(d)
+	createOpts := getCreateContainerOptions(d, meta.(*CompositeClient).DefaultMetadata)
 	log.Printf("[DEBUG] Container create configuration: %#v", createOpts)
 	container_path := containerPath(d)
 	log.Printf("[DEBUG] Create path is: %s", container_path)
@@ -137,7 +151,7 @@ func resourceBrightboxContainerUpdate(
 	client := meta.(*CompositeClient).OrbitClient
 
 	log.Printf("[INFO] Updating Container")
-	updateOpts := getUpdateContainerOptions(d)
+	updateOpts := getUpdateContainerOptions(d, meta.(*CompositeClient).DefaultMetadata)
 	log.Printf("[INFO] Container update configuration: %#v", updateOpts)
 	container, err := containers.Update(client, d.Id(), updateOpts).Extract()
 	if err != nil {
@@ -162,7 +176,60 @@ func resourceBrightboxContainerRead(
 	}
 	log.Printf("[INFO] Container read with TransID %s", getresult.TransID)
 	metadata, _ := result.ExtractMetadata()
-	return setContainerAttributes(d, getresult, metadata)
+	if err := setContainerSyncAttributes(d, result.Header); err != nil {
+		return err
+	}
+	d.Set("url", containerURL(client.ResourceBaseURL(), d.Id()))
+	return setContainerAttributes(d, getresult, metadata, meta.(*CompositeClient).DefaultMetadata)
+}
+
+func resourceBrightboxContainerCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	return planMetadataAll(d, meta.(*CompositeClient).DefaultMetadata)
+}
+
+// Plan the metadata a container or object will end up with, so that a
+// change to the provider's default_metadata shows as a diff.
+func planMetadataAll(d *schema.ResourceDiff, defaults map[string]string) error {
+	if !d.NewValueKnown("metadata") {
+		return d.SetNewComputed("metadata_all")
+	}
+	merged := mergedMetadata(defaults, d.Get("metadata").(map[string]interface{}))
+	if !reflect.DeepEqual(merged, d.Get("metadata_all").(map[string]interface{})) {
+		return d.SetNew("metadata_all", merged)
+	}
+	return nil
+}
+
+// Merge the provider's default_metadata with the resource's own
+// metadata, which takes precedence.
+func mergedMetadata(defaults map[string]string, metadata map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(defaults)+len(metadata))
+	for k, v := range defaults {
+		result[k] = v
+	}
+	for k, v := range metadata {
+		result[strings.ToLower(k)] = v
+	}
+	return result
+}
+
+// Drop the entries that only come from the provider's default_metadata,
+// so they don't appear as drift in the resource's own metadata. An
+// entry is kept if it is configured on the resource or differs from
+// the default.
+func ownMetadata(
+	all map[string]string,
+	defaults map[string]string,
+	configured map[string]interface{},
+) map[string]string {
+	result := make(map[string]string, len(all))
+	for k, v := range all {
+		if default_value, ok := defaults[k]; ok && default_value == v && configured[k] == nil {
+			continue
+		}
+		result[k] = v
+	}
+	return result
 }
 
 //func resourceBrightboxContainerExists(
@@ -182,6 +249,12 @@ func containerPath(
 	return d.Get("name").(string)
 }
 
+// Orbit has no CDN. A container is public when its read ACL includes
+// ".r:*", and is then served from its URL in the account's storage.
+func containerURL(base string, container_path string) string {
+	return base + url.PathEscape(container_path)
+}
+
 func setUnescapedString(d *schema.ResourceData, elem string, inputString string) error {
 	temp, err := url.PathUnescape(inputString)
 	if err != nil {
@@ -193,6 +266,8 @@ func setUnescapedString(d *schema.ResourceData, elem string, inputString string)
 func setUnescapedStringSet(d *schema.ResourceData, elem string, inputStringSet []string) error {
 	var tempSet []string
 	for _, str := range inputStringSet {
+		// ACLs set by other tools may have spaces after the commas
+		str = strings.TrimSpace(str)
 		if str != "" {
 			temp, err := url.PathUnescape(str)
 			if err != nil {
@@ -204,23 +279,24 @@ func setUnescapedStringSet(d *schema.ResourceData, elem string, inputStringSet [
 	return d.Set(elem, tempSet)
 }
 
-func setUnescapedStringMap(d *schema.ResourceData, elem string, inputMap map[string]string) error {
+func unescapedStringMap(inputMap map[string]string) (map[string]string, error) {
 	dest := make(map[string]string)
 	source := inputMap
 	for k, v := range source {
 		temp, err := url.PathUnescape(v)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		dest[strings.ToLower(k)] = temp
 	}
-	return d.Set(elem, dest)
+	return dest, nil
 }
 
 func setContainerAttributes(
 	d *schema.ResourceData,
 	attr *containers.GetHeader,
 	metadata map[string]string,
+	default_metadata map[string]string,
 ) error {
 	log.Printf("[DEBUG] Setting Container details from %#v", attr)
 	if err := setUnescapedString(d, "name", d.Id()); err != nil {
@@ -238,7 +314,15 @@ func setContainerAttributes(
 	if err := setUnescapedString(d, "history_location", attr.HistoryLocation); err != nil {
 		return err
 	}
-	if err := setUnescapedStringMap(d, "metadata", metadata); err != nil {
+	metadata_all, err := unescapedStringMap(metadata)
+	if err != nil {
+		return err
+	}
+	if err := d.Set("metadata_all", metadata_all); err != nil {
+		return err
+	}
+	own_metadata := ownMetadata(metadata_all, default_metadata, d.Get("metadata").(map[string]interface{}))
+	if err := d.Set("metadata", own_metadata); err != nil {
 		return err
 	}
 	//Computed
@@ -257,24 +341,66 @@ func setContainerAttributes(
 	return nil
 }
 
+// The sync settings aren't part of the extracted header. Orbit only
+// returns the sync key to the container's owner, so leave the key alone
+// when it is missing.
+func setContainerSyncAttributes(d *schema.ResourceData, header http.Header) error {
+	if err := setUnescapedString(d, "container_sync_to", header.Get("X-Container-Sync-To")); err != nil {
+		return err
+	}
+	if _, ok := header["X-Container-Sync-Key"]; ok {
+		return setUnescapedString(d, "container_sync_key", header.Get("X-Container-Sync-Key"))
+	}
+	return nil
+}
+
+// containerUpdateOpts adds the X-Remove headers Orbit uses to clear a
+// setting, which gophercloud's UpdateOpts has no fields for.
+type containerUpdateOpts struct {
+	containers.UpdateOpts
+	Remove []string
+}
+
+func (opts containerUpdateOpts) ToContainerUpdateMap() (map[string]string, error) {
+	h, err := opts.UpdateOpts.ToContainerUpdateMap()
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range opts.Remove {
+		h["X-Remove-"+header] = "yup"
+	}
+	return h, nil
+}
+
 func getUpdateContainerOptions(
 	d *schema.ResourceData,
-) *containers.UpdateOpts {
-	opts := &containers.UpdateOpts{}
+	default_metadata map[string]string,
+) *containerUpdateOpts {
+	opts := &containerUpdateOpts{}
 	opts.ContainerRead = strings.Join(escapedStringList(map_from_string_set(d, "container_read")), ",")
 	opts.ContainerWrite = strings.Join(escapedStringList(map_from_string_set(d, "container_write")), ",")
-	if attr, ok := d.GetOk("metadata"); ok {
-		opts.Metadata = escapedStringMetadata(attr)
+	metadata := mergedMetadata(default_metadata, d.Get("metadata").(map[string]interface{}))
+	if len(metadata) > 0 {
+		opts.Metadata = escapedStringMetadata(metadata)
 	}
-	if d.HasChange("metadata") {
-		old, new := d.GetChange("metadata")
-		opts.RemoveMetadata = removedMetadataKeys(old, new)
+	if d.HasChange("metadata") || d.HasChange("metadata_all") {
+		old, _ := d.GetChange("metadata")
+		old_all, _ := d.GetChange("metadata_all")
+		previous := mergedMetadata(nil, old.(map[string]interface{}))
+		for k, v := range old_all.(map[string]interface{}) {
+			previous[k] = v
+		}
+		opts.RemoveMetadata = removedMetadataKeys(previous, metadata)
 	}
 	if attr, ok := d.GetOk("container_sync_to"); ok {
 		opts.ContainerSyncTo = escapedString(attr)
+	} else if d.HasChange("container_sync_to") {
+		opts.Remove = append(opts.Remove, "Container-Sync-To")
 	}
 	if attr, ok := d.GetOk("container_sync_key"); ok {
 		opts.ContainerSyncKey = escapedString(attr)
+	} else if d.HasChange("container_sync_key") {
+		opts.Remove = append(opts.Remove, "Container-Sync-Key")
 	}
 	if attr, ok := d.GetOk("versions_location"); ok {
 		if attr == "" {
@@ -295,12 +421,14 @@ func getUpdateContainerOptions(
 
 func getCreateContainerOptions(
 	d *schema.ResourceData,
+	default_metadata map[string]string,
 ) *containers.CreateOpts {
 	opts := &containers.CreateOpts{}
 	opts.ContainerRead = strings.Join(escapedStringList(map_from_string_set(d, "container_read")), ",")
 	opts.ContainerWrite = strings.Join(escapedStringList(map_from_string_set(d, "container_write")), ",")
-	if attr, ok := d.GetOk("metadata"); ok {
-		opts.Metadata = escapedStringMetadata(attr)
+	metadata := mergedMetadata(default_metadata, d.Get("metadata").(map[string]interface{}))
+	if len(metadata) > 0 {
+		opts.Metadata = escapedStringMetadata(metadata)
 	}
 	if attr, ok := d.GetOk("container_sync_to"); ok {
 		opts.ContainerSyncTo = escapedString(attr)
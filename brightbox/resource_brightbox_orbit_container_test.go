@@ -2,10 +2,13 @@ package brightbox
 
 import (
 	"fmt"
+	"net/http"
+	"reflect"
 	"testing"
 
 	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/containers"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -36,6 +39,123 @@ func TestAccBrightboxOrbitContainer_Basic(t *testing.T) {
 	})
 }
 
+func TestAccBrightboxOrbitContainer_defaultMetadata(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxOrbitContainerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxOrbitContainerConfig_default_metadata,
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxOrbitContainerExists("brightbox_orbit_container.foobar"),
+					resource.TestCheckResourceAttr(
+						"brightbox_orbit_container.foobar", "metadata.%", "2"),
+					resource.TestCheckResourceAttr(
+						"brightbox_orbit_container.foobar", "metadata_all.%", "3"),
+					resource.TestCheckResourceAttr(
+						"brightbox_orbit_container.foobar", "metadata_all.environment", "production"),
+					resource.TestCheckResourceAttr(
+						"brightbox_orbit_container.foobar", "metadata_all.team", "web"),
+				),
+			},
+			{
+				Config:   testAccCheckBrightboxOrbitContainerConfig_default_metadata,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestMergedMetadata(t *testing.T) {
+	defaults := map[string]string{"environment": "production", "team": "ops"}
+	merged := mergedMetadata(defaults, map[string]interface{}{"team": "web", "Foo": "bar"})
+	expected := map[string]interface{}{"environment": "production", "team": "web", "foo": "bar"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Got %v, expected %v", merged, expected)
+	}
+}
+
+func TestOwnMetadata(t *testing.T) {
+	defaults := map[string]string{"environment": "production", "team": "ops", "owner": "it"}
+	all := map[string]string{"environment": "production", "team": "web", "owner": "it", "foo": "bar"}
+	own := ownMetadata(all, defaults, map[string]interface{}{"owner": "it"})
+	expected := map[string]string{"team": "web", "owner": "it", "foo": "bar"}
+	if !reflect.DeepEqual(own, expected) {
+		t.Errorf("Got %v, expected %v", own, expected)
+	}
+}
+
+func TestContainerURL(t *testing.T) {
+	base := "https://orbit.brightbox.com/v1/acc-12345/"
+	if got := containerURL(base, "assets"); got != base+"assets" {
+		t.Errorf("Got url %q", got)
+	}
+	if got := containerURL(base, "web assets"); got != base+"web%20assets" {
+		t.Errorf("Expected the container name to be escaped, got %q", got)
+	}
+}
+
+func TestSetContainerSyncAttributes(t *testing.T) {
+	d := resourceBrightboxContainer().Data(nil)
+	d.Set("container_sync_key", "secret")
+	header := http.Header{}
+	header.Set("X-Container-Sync-To", "//brightbox/gb1/acc-12345/backup")
+	if err := setContainerSyncAttributes(d, header); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("container_sync_to").(string); got != "//brightbox/gb1/acc-12345/backup" {
+		t.Errorf("Got container_sync_to %q", got)
+	}
+	if got := d.Get("container_sync_key").(string); got != "secret" {
+		t.Errorf("Expected a hidden sync key to be left alone, got %q", got)
+	}
+
+	header.Set("X-Container-Sync-Key", "changed")
+	header.Del("X-Container-Sync-To")
+	if err := setContainerSyncAttributes(d, header); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("container_sync_to").(string); got != "" {
+		t.Errorf("Expected container_sync_to to be cleared, got %q", got)
+	}
+	if got := d.Get("container_sync_key").(string); got != "changed" {
+		t.Errorf("Got container_sync_key %q, expected changed", got)
+	}
+}
+
+func TestGetUpdateContainerOptions_removeSync(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "initial",
+		Attributes: map[string]string{
+			"id":                 "initial",
+			"name":               "initial",
+			"container_sync_to":  "//brightbox/gb1/acc-12345/backup",
+			"container_sync_key": "secret",
+		},
+	}
+	diff, err := resourceBrightboxContainer().Diff(state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name": "initial",
+	}), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	d, err := schema.InternalMap(resourceBrightboxContainer().Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	headers, err := getUpdateContainerOptions(d, nil).ToContainerUpdateMap()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, header := range []string{"X-Remove-Container-Sync-To", "X-Remove-Container-Sync-Key"} {
+		if _, ok := headers[header]; !ok {
+			t.Errorf("Expected %s in %v", header, headers)
+		}
+	}
+}
+
 func TestAccBrightboxOrbitContainer_metadata(t *testing.T) {
 
 	resource.Test(t, resource.TestCase{
@@ -164,3 +284,35 @@ resource "brightbox_orbit_container" "foobar" {
 	container_read = [ "acc-testy", "acc-12345", "acc-98765" ]
 }
 `
+
+const testAccCheckBrightboxOrbitContainerConfig_default_metadata = `
+
+provider "brightbox" {
+	default_metadata = {
+		"environment" = "production"
+		"team" = "ops"
+	}
+}
+
+resource "brightbox_orbit_container" "foobar" {
+	name = "initial"
+	metadata = {
+		"team" = "web"
+		"foo" = "bar"
+	}
+}
+`
+
+const testAccCheckBrightboxOrbitContainerConfig_settings = `
+
+resource "brightbox_orbit_container" "foobar" {
+	name = "initial"
+	container_read = [ ".r:*", ".rlistings" ]
+	container_write = [ "acc-testy" ]
+	versions_location = "initial_versions"
+	metadata = {
+		"quota-bytes" = "1048576"
+		"foo" = "bar"
+	}
+}
+`
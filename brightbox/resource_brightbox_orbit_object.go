@@ -0,0 +1,363 @@
+package brightbox
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/objects"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func resourceBrightboxOrbitObject() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceBrightboxOrbitObjectCreate,
+		Read:   resourceBrightboxOrbitObjectRead,
+		Update: resourceBrightboxOrbitObjectUpdate,
+		Delete: resourceBrightboxOrbitObjectDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+		CustomizeDiff: resourceBrightboxOrbitObjectCustomizeDiff,
+
+		Schema: map[string]*schema.Schema{
+			"container": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.NoZeroValues,
+			},
+			"source": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"content"},
+			},
+			"content": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"source"},
+			},
+			"content_type": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"metadata": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				ValidateFunc: http1Keys,
+			},
+			"metadata_all": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"delete_at": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ConflictsWith:    []string{"delete_after"},
+				ValidateFunc:     validation.ValidateRFC3339TimeString,
+				DiffSuppressFunc: suppressEqualTimes,
+			},
+			"delete_after": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"delete_at"},
+				ValidateFunc:  ValidatePositiveDurationString,
+			},
+			"expires_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"etag": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"content_length": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"last_modified": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func resourceBrightboxOrbitObjectCreate(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	container := d.Get("container").(string)
+	name := d.Get("name").(string)
+	if err := uploadOrbitObject(d, meta); err != nil {
+		return err
+	}
+	d.SetId(container + "/" + name)
+	return resourceBrightboxOrbitObjectRead(d, meta)
+}
+
+func resourceBrightboxOrbitObjectRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).OrbitClient
+
+	container, name, err := orbitObjectPath(d.Id())
+	if err != nil {
+		return err
+	}
+	log.Printf("[DEBUG] Reading Orbit object: %s", d.Id())
+	result := objects.Get(client, container, name, nil)
+	header, err := result.Extract()
+	if err != nil {
+		return CheckDeleted(d, result.Err, "Orbit object")
+	}
+	log.Printf("[INFO] Orbit object read with TransID %s", header.TransID)
+	metadata, err := result.ExtractMetadata()
+	if err != nil {
+		return err
+	}
+	metadata_all, err := unescapedStringMap(metadata)
+	if err != nil {
+		return err
+	}
+
+	d.Set("container", container)
+	d.Set("name", name)
+	d.Set("content_type", header.ContentType)
+	// A changed etag leaves the planned hash of the content
+	// disagreeing with the state, so the object is uploaded again
+	d.Set("etag", strings.Trim(header.ETag, `"`))
+	d.Set("content_length", header.ContentLength)
+	d.Set("last_modified", header.LastModified.Format(time.RFC3339))
+	expires_at := ""
+	if !header.DeleteAt.IsZero() {
+		expires_at = header.DeleteAt.UTC().Format(time.RFC3339)
+	}
+	d.Set("expires_at", expires_at)
+	// delete_after can't be read back, as Orbit turns it into a time
+	if _, ok := d.GetOk("delete_at"); ok {
+		d.Set("delete_at", expires_at)
+	}
+	if err := d.Set("metadata_all", metadata_all); err != nil {
+		return err
+	}
+	default_metadata := meta.(*CompositeClient).DefaultMetadata
+	return d.Set("metadata", ownMetadata(metadata_all, default_metadata, d.Get("metadata").(map[string]interface{})))
+}
+
+func resourceBrightboxOrbitObjectUpdate(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	if d.HasChange("etag") || d.HasChange("source") || d.HasChange("content") {
+		if err := uploadOrbitObject(d, meta); err != nil {
+			return err
+		}
+		return resourceBrightboxOrbitObjectRead(d, meta)
+	}
+
+	client := meta.(*CompositeClient).OrbitClient
+	container, name, err := orbitObjectPath(d.Id())
+	if err != nil {
+		return err
+	}
+	// Orbit replaces all of an object's metadata on update, including
+	// its expiry, so removed keys need no special handling but the
+	// expiry has to be sent again
+	opts := objects.UpdateOpts{
+		Metadata:    escapedStringMetadata(orbitObjectMetadata(d, meta)),
+		ContentType: d.Get("content_type").(string),
+	}
+	opts.DeleteAt, opts.DeleteAfter, err = orbitObjectExpiry(d, false)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Updating Orbit object %s metadata: %#v", d.Id(), opts)
+	result, err := objects.Update(client, container, name, opts).Extract()
+	if err != nil {
+		return fmt.Errorf("Error updating Orbit object %s: %s", d.Id(), err)
+	}
+	log.Printf("[INFO] Orbit object updated with TransID %s", result.TransID)
+	return resourceBrightboxOrbitObjectRead(d, meta)
+}
+
+func resourceBrightboxOrbitObjectDelete(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).OrbitClient
+
+	container, name, err := orbitObjectPath(d.Id())
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Deleting Orbit object %s", d.Id())
+	result := objects.Delete(client, container, name, nil)
+	if _, err := result.Extract(); err != nil {
+		return CheckDeleted(d, result.Err, "Orbit object")
+	}
+	return nil
+}
+
+func resourceBrightboxOrbitObjectCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if err := planOrbitObjectEtag(d); err != nil {
+		return err
+	}
+	return planMetadataAll(d, meta.(*CompositeClient).DefaultMetadata)
+}
+
+// Plan the etag the object will have once uploaded, so that a change
+// to the source file shows as a diff even though its path is the same.
+func planOrbitObjectEtag(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown("source") || !d.NewValueKnown("content") {
+		return d.SetNewComputed("etag")
+	}
+	etag, err := orbitObjectContentMD5(d.Get("source").(string), d.Get("content").(string))
+	if err != nil {
+		return err
+	}
+	if etag != d.Get("etag").(string) {
+		return d.SetNew("etag", etag)
+	}
+	return nil
+}
+
+// The object's own metadata merged with the provider's default_metadata
+func orbitObjectMetadata(d *schema.ResourceData, meta interface{}) map[string]interface{} {
+	return mergedMetadata(meta.(*CompositeClient).DefaultMetadata, d.Get("metadata").(map[string]interface{}))
+}
+
+func uploadOrbitObject(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).OrbitClient
+
+	container := d.Get("container").(string)
+	name := d.Get("name").(string)
+	content, err := openOrbitObjectContent(d.Get("source").(string), d.Get("content").(string))
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	opts := objects.CreateOpts{
+		Content:     content,
+		Metadata:    escapedStringMetadata(orbitObjectMetadata(d, meta)),
+		ContentType: d.Get("content_type").(string),
+		// Orbit refuses the upload if the content no longer matches
+		// the plan
+		ETag: d.Get("etag").(string),
+	}
+	opts.DeleteAt, opts.DeleteAfter, err = orbitObjectExpiry(d, true)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Uploading Orbit object %s/%s", container, name)
+	result, err := objects.Create(client, container, name, opts).Extract()
+	if err != nil {
+		return fmt.Errorf("Error uploading Orbit object %s/%s: %s", container, name, err)
+	}
+	log.Printf("[INFO] Orbit object uploaded with TransID %s", result.TransID)
+	return nil
+}
+
+// The X-Delete-At and X-Delete-After to send with an object. An object
+// uploaded again starts its delete_after period afresh, while an update
+// to its metadata keeps the current expiry unless delete_after changed.
+func orbitObjectExpiry(d *schema.ResourceData, upload bool) (int, int, error) {
+	if delete_at, ok := d.GetOk("delete_at"); ok {
+		when, err := time.Parse(time.RFC3339, delete_at.(string))
+		if err != nil {
+			return 0, 0, err
+		}
+		return int(when.Unix()), 0, nil
+	}
+	delete_after, ok := d.GetOk("delete_after")
+	if !ok {
+		return 0, 0, nil
+	}
+	if upload || d.HasChange("delete_after") {
+		duration, err := time.ParseDuration(delete_after.(string))
+		if err != nil {
+			return 0, 0, err
+		}
+		return 0, int(duration.Seconds()), nil
+	}
+	if expires_at, ok := d.GetOk("expires_at"); ok {
+		when, err := time.Parse(time.RFC3339, expires_at.(string))
+		if err != nil {
+			return 0, 0, err
+		}
+		return int(when.Unix()), 0, nil
+	}
+	return 0, 0, nil
+}
+
+// Times given with a different offset are the same expiry
+func suppressEqualTimes(k, old, new string, d *schema.ResourceData) bool {
+	old_time, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+	new_time, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+	return old_time.Equal(new_time)
+}
+
+// The object's content comes from the source file if given, otherwise
+// from the content string
+func openOrbitObjectContent(source string, content string) (io.ReadCloser, error) {
+	if source == "" {
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	}
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening Orbit object source: %s", err)
+	}
+	return file, nil
+}
+
+// Orbit gives the MD5 of an object's content as its etag
+func orbitObjectContentMD5(source string, content string) (string, error) {
+	reader, err := openOrbitObjectContent(source, content)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	hash := md5.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", fmt.Errorf("Error reading Orbit object source: %s", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func orbitObjectPath(id string) (string, string, error) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("Invalid Orbit object id %q, expected container/object", id)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,310 @@
+package brightbox
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/objects"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccBrightboxOrbitObject_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxOrbitObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxOrbitObjectConfig("hello\n"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"brightbox_orbit_object.foobar", "etag", "b1946ac92492d2347c6235b4d2611184"),
+					resource.TestCheckResourceAttr(
+						"brightbox_orbit_object.foobar", "content_type", "text/plain"),
+					resource.TestCheckResourceAttr(
+						"brightbox_orbit_object.foobar", "metadata.release", "1"),
+				),
+			},
+			{
+				Config: testAccCheckBrightboxOrbitObjectConfig("goodbye\n"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"brightbox_orbit_object.foobar", "content_length", "8"),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxOrbitObjectDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*CompositeClient).OrbitClient
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "brightbox_orbit_object" {
+			continue
+		}
+		container, name, err := orbitObjectPath(rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		result := objects.Get(client, container, name, nil)
+		if _, ok := result.Err.(gophercloud.ErrDefault404); !ok {
+			return fmt.Errorf("Orbit object %s still exists: %v", rs.Primary.ID, result.Err)
+		}
+	}
+	return nil
+}
+
+func testAccCheckBrightboxOrbitObjectConfig(content string) string {
+	return fmt.Sprintf(`
+resource "brightbox_orbit_container" "foobar" {
+	name = "objects"
+}
+
+resource "brightbox_orbit_object" "foobar" {
+	container = "${brightbox_orbit_container.foobar.name}"
+	name = "releases/notes.txt"
+	content = %q
+	content_type = "text/plain"
+	metadata = {
+		release = "1"
+	}
+}
+`, content)
+}
+
+func TestOrbitObjectPath(t *testing.T) {
+	container, name, err := orbitObjectPath("releases/app/1.0.tar.gz")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if container != "releases" || name != "app/1.0.tar.gz" {
+		t.Errorf("Got %q and %q", container, name)
+	}
+	for _, id := range []string{"releases", "releases/", "/app"} {
+		if _, _, err := orbitObjectPath(id); err == nil {
+			t.Errorf("Expected an error for id %q", id)
+		}
+	}
+}
+
+func TestOrbitObjectCustomizeDiff_source(t *testing.T) {
+	source, err := ioutil.TempFile("", "orbit-object")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(source.Name())
+	source.WriteString("hello\n")
+	source.Close()
+
+	state := &terraform.InstanceState{
+		ID: "objects/notes.txt",
+		Attributes: map[string]string{
+			"id":        "objects/notes.txt",
+			"container": "objects",
+			"name":      "notes.txt",
+			"source":    source.Name(),
+			"etag":      "b1946ac92492d2347c6235b4d2611184",
+		},
+	}
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"container": "objects",
+		"name":      "notes.txt",
+		"source":    source.Name(),
+	})
+	diff, err := resourceBrightboxOrbitObject().Diff(state, config, &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil && diff.Attributes["etag"] != nil {
+		t.Errorf("Expected no change for an unchanged source, got %#v", diff.Attributes["etag"])
+	}
+
+	ioutil.WriteFile(source.Name(), []byte("goodbye\n"), 0644)
+	diff, err = resourceBrightboxOrbitObject().Diff(state, config, &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Attributes["etag"] == nil {
+		t.Fatalf("Expected a changed source to change the etag")
+	}
+	if diff.RequiresNew() {
+		t.Errorf("Expected the object to be uploaded again in place")
+	}
+	expected, _ := orbitObjectContentMD5("", "goodbye\n")
+	if got := diff.Attributes["etag"].New; got != expected {
+		t.Errorf("Got planned etag %q, expected %q", got, expected)
+	}
+}
+
+func TestOrbitObjectLifecycle(t *testing.T) {
+	var stored []byte
+	var headers http.Header
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/objects/releases/notes.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case "PUT":
+			stored, _ = ioutil.ReadAll(r.Body)
+			headers = r.Header
+			w.WriteHeader(http.StatusCreated)
+		case "GET", "HEAD":
+			if stored == nil {
+				http.NotFound(w, r)
+				return
+			}
+			etag, _ := orbitObjectContentMD5("", string(stored))
+			w.Header().Set("Etag", etag)
+			w.Header().Set("Content-Type", headers.Get("Content-Type"))
+			w.Header().Set("X-Object-Meta-Release", headers.Get("X-Object-Meta-Release"))
+			w.Header().Set("X-Object-Meta-Team", headers.Get("X-Object-Meta-Team"))
+			w.Header().Set("Last-Modified", "Mon, 01 Jul 2019 09:00:00 GMT")
+			if delete_after := headers.Get("X-Delete-After"); delete_after != "" {
+				w.Header().Set("X-Delete-At", "1562058000")
+			}
+			w.Write(stored)
+		case "DELETE":
+			stored = nil
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer server.Close()
+	meta.DefaultMetadata = map[string]string{"team": "web"}
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"container":    "objects",
+		"name":         "releases/notes.txt",
+		"content":      "hello\n",
+		"content_type": "text/plain",
+		"metadata":     map[string]interface{}{"release": "1"},
+		"delete_after": "24h",
+	})
+	diff, err := resourceBrightboxOrbitObject().Diff(nil, config, meta)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	d, err := schema.InternalMap(resourceBrightboxOrbitObject().Schema).Data(nil, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := resourceBrightboxOrbitObjectCreate(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "objects/releases/notes.txt" {
+		t.Errorf("Got id %q", d.Id())
+	}
+	if string(stored) != "hello\n" {
+		t.Errorf("Got uploaded content %q", stored)
+	}
+	if got := headers.Get("Etag"); got != "b1946ac92492d2347c6235b4d2611184" {
+		t.Errorf("Expected the planned etag to be sent, got %q", got)
+	}
+	if got := d.Get("metadata.release").(string); got != "1" {
+		t.Errorf("Got metadata release %q", got)
+	}
+	if got := headers.Get("X-Object-Meta-Team"); got != "web" {
+		t.Errorf("Expected default_metadata to be sent, got team %q", got)
+	}
+	if _, ok := d.GetOk("metadata.team"); ok {
+		t.Errorf("Expected default_metadata to be left out of metadata")
+	}
+	if got := d.Get("metadata_all.team").(string); got != "web" {
+		t.Errorf("Got metadata_all team %q", got)
+	}
+	if got := d.Get("content_type").(string); got != "text/plain" {
+		t.Errorf("Got content type %q", got)
+	}
+	if got := headers.Get("X-Delete-After"); got != "86400" {
+		t.Errorf("Expected delete_after to be sent in seconds, got %q", got)
+	}
+	if got := d.Get("expires_at").(string); got != "2019-07-02T09:00:00Z" {
+		t.Errorf("Got expires_at %q", got)
+	}
+
+	if err := resourceBrightboxOrbitObjectDelete(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := resourceBrightboxOrbitObjectRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "" {
+		t.Errorf("Expected a deleted object to be removed from state")
+	}
+}
+
+func TestOrbitObjectExpiry(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "objects/notes.txt",
+		Attributes: map[string]string{
+			"id":           "objects/notes.txt",
+			"container":    "objects",
+			"name":         "notes.txt",
+			"content":      "hello\n",
+			"delete_after": "24h",
+			"expires_at":   "2030-01-01T00:00:00Z",
+			"etag":         "b1946ac92492d2347c6235b4d2611184",
+		},
+	}
+	expiry := func(state *terraform.InstanceState, raw map[string]interface{}, upload bool) (int, int) {
+		raw["container"] = "objects"
+		raw["name"] = "notes.txt"
+		raw["content"] = "hello\n"
+		config := terraform.NewResourceConfigRaw(raw)
+		diff, err := resourceBrightboxOrbitObject().Diff(state, config, &CompositeClient{})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		d, err := schema.InternalMap(resourceBrightboxOrbitObject().Schema).Data(state, diff)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		delete_at, delete_after, err := orbitObjectExpiry(d, upload)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return delete_at, delete_after
+	}
+	const expires = 1893456000
+
+	if at, after := expiry(nil, map[string]interface{}{"delete_after": "24h"}, true); at != 0 || after != 86400 {
+		t.Errorf("Expected a new object to expire a day after upload, got %d, %d", at, after)
+	}
+	if at, after := expiry(nil, map[string]interface{}{"delete_at": "2030-01-01T01:00:00+01:00"}, true); at != expires || after != 0 {
+		t.Errorf("Expected delete_at to be sent as a time, got %d, %d", at, after)
+	}
+	raw := map[string]interface{}{"delete_after": "24h", "metadata": map[string]interface{}{"release": "2"}}
+	if at, after := expiry(state, raw, false); at != expires || after != 0 {
+		t.Errorf("Expected a metadata update to keep the current expiry, got %d, %d", at, after)
+	}
+	if at, after := expiry(state, map[string]interface{}{"delete_after": "48h"}, false); at != 0 || after != 172800 {
+		t.Errorf("Expected a changed delete_after to restart the period, got %d, %d", at, after)
+	}
+	if at, after := expiry(state, map[string]interface{}{}, false); at != 0 || after != 0 {
+		t.Errorf("Expected a removed delete_after to clear the expiry, got %d, %d", at, after)
+	}
+}
+
+func TestOrbitObjectExpiryValidation(t *testing.T) {
+	for _, value := range []string{"-24h", "0s", "tomorrow"} {
+		if _, errs := ValidatePositiveDurationString(value, "delete_after"); len(errs) == 0 {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+	if _, errs := ValidatePositiveDurationString("720h", "delete_after"); len(errs) != 0 {
+		t.Errorf("Expected 720h to be valid, got %v", errs)
+	}
+	if !suppressEqualTimes("delete_at", "2030-01-01T00:00:00Z", "2030-01-01T01:00:00+01:00", nil) {
+		t.Errorf("Expected the same time with a different offset to be suppressed")
+	}
+	if suppressEqualTimes("delete_at", "2030-01-01T00:00:00Z", "2030-01-02T00:00:00Z", nil) {
+		t.Errorf("Expected a different time to show as a diff")
+	}
+}
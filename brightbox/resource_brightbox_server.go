@@ -1,18 +1,33 @@
 package brightbox
 
 import (
+	"encoding/base64"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 )
 
 const (
+	// Default for the user_data_limit provider option
 	userdata_size_limit = 16384
 )
 
+var imageIdRe = regexp.MustCompile("^img-[0-9a-z]{5}$")
+var serverGroupIdRe = regexp.MustCompile("^grp-[0-9a-z]{5}$")
+var serverIdRe = regexp.MustCompile("^srv-[0-9a-z]{5}$")
+var orbitObjectPathRe = regexp.MustCompile("^[^/]+/.+$")
+
 func resourceBrightboxServer() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceBrightboxServerCreate,
@@ -22,9 +37,11 @@ func resourceBrightboxServer() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
 		},
+		CustomizeDiff: resourceBrightboxServerCustomizeDiff,
 
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(defaultTimeout),
+			Update: schema.DefaultTimeout(defaultTimeout),
 			Delete: schema.DefaultTimeout(defaultTimeout),
 		},
 
@@ -35,39 +52,129 @@ func resourceBrightboxServer() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"image_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
 			"name": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressGeneratedName,
+			},
+
+			"generated_name": {
 				Type:     schema.TypeString,
-				Optional: true,
+				Computed: true,
 			},
 
 			"type": {
 				Type:     schema.TypeString,
 				Optional: true,
 				Computed: true,
-				ForceNew: true,
+			},
+
+			"ram": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"type"},
+				ValidateFunc:  validation.IntAtLeast(1),
+			},
+
+			"cores": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"type"},
+				ValidateFunc:  validation.IntAtLeast(1),
 			},
 
 			"zone": {
-				Type:     schema.TypeString,
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				DiffSuppressFunc: suppressFallbackZone,
+			},
+
+			"zone_fallback": {
+				Type:     schema.TypeList,
 				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
+			"zone_requested": {
+				Type:     schema.TypeString,
 				Computed: true,
-				ForceNew: true,
 			},
 
 			"user_data": {
 				Type:          schema.TypeString,
 				Optional:      true,
-				ConflictsWith: []string{"user_data_base64"},
+				ConflictsWith: []string{"user_data_base64", "user_data_parts", "user_data_orbit_object"},
 				StateFunc:     hash_string,
+				ValidateFunc:  warnIfBase64Encoded,
 			},
 
 			"user_data_base64": {
 				Type:          schema.TypeString,
 				Optional:      true,
-				ConflictsWith: []string{"user_data"},
+				ConflictsWith: []string{"user_data", "user_data_parts", "user_data_orbit_object", "user_data_gzip"},
 				ValidateFunc:  mustBeBase64Encoded,
 			},
 
+			"user_data_gzip": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"user_data_base64"},
+			},
+
+			"user_data_parts": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"user_data", "user_data_base64", "user_data_orbit_object"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"content": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"content_type": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  defaultPartType,
+						},
+						"filename": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+
+			"user_data_orbit_object": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"user_data", "user_data_base64", "user_data_parts"},
+				ValidateFunc: validation.StringMatch(
+					orbitObjectPathRe,
+					"must be the container name and object name separated by a slash",
+				),
+			},
+
+			"expose_user_data": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"user_data_plaintext": {
+				Type:      schema.TypeString,
+				Computed:  true,
+				Sensitive: true,
+			},
+
 			"server_groups": {
 				Type:     schema.TypeSet,
 				Required: true,
@@ -76,21 +183,101 @@ func resourceBrightboxServer() *schema.Resource {
 				Set:      schema.HashString,
 			},
 
+			"load_balancer": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+
+			"snapshot_on_recreate": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"recreated_from": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"reboot_triggers": {
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+
 			"status": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
 
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"started_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"console_available": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
 			"locked": {
 				Type:     schema.TypeBool,
+				Optional: true,
 				Computed: true,
 			},
 
+			"wait_for_cloud_init": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"cloud_init_port": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 65535),
+			},
+
 			"interface": {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
 
+			"mac_address": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"interfaces": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mac_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ipv4_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ipv6_address": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+
 			"ipv6_address": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -106,6 +293,21 @@ func resourceBrightboxServer() *schema.Resource {
 				Computed: true,
 			},
 
+			"public_ipv4": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"public_ipv6": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"egress_ip": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
 			"hostname": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -129,6 +331,44 @@ func resourceBrightboxServer() *schema.Resource {
 				Type:     schema.TypeString,
 				Computed: true,
 			},
+
+			"os_family": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"connection_user": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"connection_settings": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"ssh", "winrm"}, false),
+						},
+						"user": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"port": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(minPort, maxPort),
+						},
+						"https": {
+							Type:     schema.TypeBool,
+							Optional: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -138,47 +378,297 @@ func resourceBrightboxServerCreate(
 	meta interface{},
 ) error {
 	client := meta.(*CompositeClient).ApiClient
+	deadline := time.Now().Add(meta.(*CompositeClient).timeout(d, schema.TimeoutCreate))
 
 	log.Printf("[DEBUG] Server create called")
+	image, err := serverImageId(client, d.Get("image").(string))
+	if err != nil {
+		return err
+	}
 	server_opts := &brightbox.ServerOptions{
-		Image: d.Get("image").(string),
+		Image: image,
 	}
 
-	err := addUpdateableServerOptions(d, server_opts)
+	err = addUpdateableServerOptions(d, server_opts, meta.(*CompositeClient))
 	if err != nil {
 		return err
 	}
+	assignGeneratedName(d, &server_opts.Name, meta.(*CompositeClient).NamePrefix)
 
 	server_type := &server_opts.ServerType
 	assign_string(d, &server_type, "type")
+	if server_opts.ServerType == "" {
+		handle, err := serverTypeHandleFromSpec(d, client)
+		if err != nil {
+			return err
+		}
+		server_opts.ServerType = handle
+	}
 	zone := &server_opts.Zone
 	assign_string(d, &zone, "zone")
 
 	log.Printf("[DEBUG] Server create configuration: %#v", server_opts)
 
-	server, err := client.CreateServer(server_opts)
+	requested_zone := server_opts.Zone
+	server, err := createServerWithFallback(client, server_opts, map_from_string_list(d.Get("zone_fallback").([]interface{})))
 	if err != nil {
 		return fmt.Errorf("Error creating server: %s", err)
 	}
 
 	d.SetId(server.Id)
+	setZoneRequested(d, requested_zone, server_opts.Zone)
+
+	active_server, err := waitForServerAvailable(client, server.Id, time.Until(deadline))
+	if err != nil {
+		return serverCreateStepError(d.Id(), "waiting for it to become available", err)
+	}
 
-	log.Printf("[INFO] Waiting for Server (%s) to become available", d.Id())
+	if step, err := setupServer(d, client, active_server, deadline); err != nil {
+		setServerAttributes(d, active_server)
+		return serverCreateStepError(d.Id(), step, err)
+	}
+
+	return setServerAttributes(d, active_server)
+}
+
+func waitForServerAvailable(
+	client *brightbox.Client,
+	server_id string,
+	timeout time.Duration,
+) (*brightbox.Server, error) {
+	log.Printf("[INFO] Waiting for Server (%s) to become available", server_id)
 
 	stateConf := resource.StateChangeConf{
 		Pending:    []string{"creating"},
 		Target:     []string{"active", "inactive"},
-		Refresh:    serverStateRefresh(client, server.Id),
-		Timeout:    d.Timeout(schema.TimeoutCreate),
+		Refresh:    serverStateRefresh(client, server_id),
+		Timeout:    timeout,
 		Delay:      checkDelay,
 		MinTimeout: minimumRefreshWait,
 	}
 	active_server, err := stateConf.WaitForState()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return active_server.(*brightbox.Server), nil
+}
+
+// Carry out the steps that follow building a server, returning the step
+// that failed, if any. Waits end at the deadline of the whole operation.
+func setupServer(
+	d *schema.ResourceData,
+	client *brightbox.Client,
+	server *brightbox.Server,
+	deadline time.Time,
+) (string, error) {
+	if load_balancer_id, ok := d.GetOk("load_balancer"); ok {
+		err := addServerToLoadBalancer(client, server.Id, load_balancer_id.(string))
+		if err != nil {
+			// Leave the membership out of state so the next apply adds it
+			d.Set("load_balancer", "")
+			return "adding it to the load balancer", err
+		}
+	}
+
+	if d.Get("locked").(bool) {
+		if err := setServerLock(client, server.Id, true); err != nil {
+			return "locking it", err
+		}
+		server.Locked = true
+	}
+
+	if d.Get("wait_for_cloud_init").(bool) {
+		setServerAttributes(d, server)
+		if err := waitForCloudInit(d, deadline); err != nil {
+			return "waiting for cloud-init", err
+		}
+	}
+	return "", nil
+}
+
+// Brightbox can't tell when cloud-init has finished, so wait for the
+// server to accept connections on a port. That is the port provisioners
+// connect to unless cloud_init_port names one that cloud-init opens as
+// its last step. Without it, this only shows that SSH is reachable.
+func waitForCloudInit(d *schema.ResourceData, deadline time.Time) error {
+	conn_info := d.ConnInfo()
+	host := conn_info["host"]
+	if host == "" {
+		return fmt.Errorf("Server %s has no address to check", d.Id())
+	}
+	port := conn_info["port"]
+	if attr, ok := d.GetOk("cloud_init_port"); ok {
+		port = strconv.Itoa(attr.(int))
+	} else if port == "" {
+		port = "22"
+	}
+	address := net.JoinHostPort(host, port)
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return fmt.Errorf("No time was left to wait for %s to accept a connection", address)
+	}
+	log.Printf("[INFO] Waiting for Server (%s) to accept connections on %s", d.Id(), address)
+	stateConf := resource.StateChangeConf{
+		Pending:    []string{"waiting"},
+		Target:     []string{"ready"},
+		Refresh:    serverPortRefresh(address),
+		Timeout:    timeout,
+		Delay:      checkDelay,
+		MinTimeout: minimumRefreshWait,
+	}
+	_, err := stateConf.WaitForState()
+	if err != nil {
+		return fmt.Errorf("%s never accepted a connection, so cloud-init did not signal completion: %s", address, err)
 	}
+	return nil
+}
 
-	return setServerAttributes(d, active_server.(*brightbox.Server))
+func serverPortRefresh(address string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		conn, err := net.DialTimeout("tcp", address, minimumRefreshWait)
+		if err != nil {
+			log.Printf("[DEBUG] %s not ready: %s", address, err)
+			return address, "waiting", nil
+		}
+		conn.Close()
+		return address, "ready", nil
+	}
+}
+
+// A server type that has been withdrawn may come back without its
+// details. Keep the last known values rather than produce a diff that
+// would replace the server.
+func setServerTypeAttributes(
+	d *schema.ResourceData,
+	server *brightbox.Server,
+) {
+	server_type := server.ServerType
+	if server_type.Handle == "" {
+		log.Printf("[WARN] Server %s has an unrecognised server type %q, keeping last known type %q",
+			server.Id, server_type.Id, d.Get("type").(string))
+		return
+	}
+	d.Set("type", server_type.Handle)
+	if server_type.Ram > 0 {
+		d.Set("ram", server_type.Ram)
+	}
+	if server_type.Cores > 0 {
+		d.Set("cores", server_type.Cores)
+	}
+}
+
+// Images can be given by id or by a regex matching the image name. A name
+// resolves to the most recent matching available image, preferring
+// official images.
+func serverImageId(client *brightbox.Client, image string) (string, error) {
+	if imageIdRe.MatchString(image) {
+		return image, nil
+	}
+	log.Printf("[DEBUG] Looking up image named %q", image)
+	images, err := client.Images()
+	if err != nil {
+		return "", fmt.Errorf("Error retrieving image list: %s", err)
+	}
+	result, err := findImageByName(images, image)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[INFO] Image %q resolved to %s (%s)", image, result.Id, result.Name)
+	return result.Id, nil
+}
+
+func findImageByName(
+	images []brightbox.Image,
+	name string,
+) (*brightbox.Image, error) {
+	nameRe, err := regexp.Compile(name)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid image name %q: %s", name, err)
+	}
+	var results, official []brightbox.Image
+	for _, image := range images {
+		if !validImageStatus[image.Status] || !nameRe.MatchString(image.Name) {
+			continue
+		}
+		results = append(results, image)
+		if image.Official {
+			official = append(official, image)
+		}
+	}
+	if len(official) > 0 {
+		results = official
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("No available image matches the name %q", name)
+	}
+	return mostRecentImage(results), nil
+}
+
+// Once the server exists any later failure in Create leaves it in state
+// as tainted. Say what has been done and how to keep the server rather
+// than have the next apply replace it.
+func serverCreateStepError(server_id string, step string, err error) error {
+	return fmt.Errorf("Server %s was created but %s failed: %s. "+
+		"To keep the server run 'terraform untaint' on it, then apply again to complete the remaining steps",
+		server_id, step, err)
+}
+
+// Resolve the server type from the ram and cores specification, if
+// given, returning an empty handle when neither is set
+func serverTypeHandleFromSpec(
+	d *schema.ResourceData,
+	client *brightbox.Client,
+) (string, error) {
+	ram := d.Get("ram").(int)
+	cores := d.Get("cores").(int)
+	if ram == 0 && cores == 0 {
+		return "", nil
+	}
+	log.Printf("[DEBUG] Looking up server type with %dMB RAM and %d cores", ram, cores)
+	server_types, err := client.ServerTypes()
+	if err != nil {
+		return "", fmt.Errorf("Error retrieving server type list: %s", err)
+	}
+	server_type, err := findServerTypeBySpec(server_types, ram, cores)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[DEBUG] Resolved server type %s", server_type.Handle)
+	return server_type.Handle, nil
+}
+
+// Find the single available server type with the required ram and
+// cores. A zero value matches any size.
+func findServerTypeBySpec(
+	server_types []brightbox.ServerType,
+	ram int,
+	cores int,
+) (*brightbox.ServerType, error) {
+	var results []brightbox.ServerType
+	for _, server_type := range server_types {
+		if server_type.Status != "available" {
+			continue
+		}
+		if ram != 0 && server_type.Ram != ram {
+			continue
+		}
+		if cores != 0 && server_type.Cores != cores {
+			continue
+		}
+		results = append(results, server_type)
+	}
+	if len(results) == 1 {
+		return &results[0], nil
+	} else if len(results) > 1 {
+		handles := make([]string, len(results))
+		for i, server_type := range results {
+			handles[i] = server_type.Handle
+		}
+		return nil, fmt.Errorf("More than one server type matches %dMB RAM and %d cores: %s. "+
+			"Please specify the type directly.", ram, cores, strings.Join(handles, ", "))
+	} else {
+		return nil, fmt.Errorf("No server type matches %dMB RAM and %d cores", ram, cores)
+	}
 }
 
 func resourceBrightboxServerRead(
@@ -198,6 +688,11 @@ func resourceBrightboxServerRead(
 		return nil
 	}
 
+	err = setServerLoadBalancer(d, client)
+	if err != nil {
+		return err
+	}
+
 	return setServerAttributes(d, server)
 }
 
@@ -208,24 +703,50 @@ func resourceBrightboxServerDelete(
 	client := meta.(*CompositeClient).ApiClient
 
 	log.Printf("[DEBUG] Server delete called for %s", d.Id())
-	err := client.DestroyServer(d.Id())
+	server, err := client.Server(d.Id())
+	if err != nil {
+		return fmt.Errorf("Error retrieving server details: %s", err)
+	}
+	if server.Locked {
+		return fmt.Errorf("Server %s is locked and cannot be deleted. "+
+			"Set locked to false and apply before deleting it", d.Id())
+	}
+	err = destroyServer(client, d.Id(), d.Get("load_balancer").(string), meta.(*CompositeClient).timeout(d, schema.TimeoutDelete))
+	if err != nil {
+		return err
+	}
+	d.SetId("")
+	return nil
+}
+
+// Take the server out of the load balancer, if any, then destroy it and
+// wait until it has gone
+func destroyServer(
+	client *brightbox.Client,
+	server_id string,
+	load_balancer_id string,
+	timeout time.Duration,
+) error {
+	if load_balancer_id != "" {
+		err := removeServerFromLoadBalancer(client, server_id, load_balancer_id)
+		if err != nil {
+			return err
+		}
+	}
+	err := client.DestroyServer(server_id)
 	if err != nil {
 		return fmt.Errorf("Error deleting server: %s", err)
 	}
 	stateConf := resource.StateChangeConf{
-		Pending:    []string{"deleting", "active", "inactive"},
+		Pending:    []string{"deleting", "active", "inactive", "failed"},
 		Target:     []string{"deleted"},
-		Refresh:    serverStateRefresh(client, d.Id()),
-		Timeout:    d.Timeout(schema.TimeoutDelete),
+		Refresh:    serverStatusRefresh(client, server_id),
+		Timeout:    timeout,
 		Delay:      checkDelay,
 		MinTimeout: minimumRefreshWait,
 	}
 	_, err = stateConf.WaitForState()
-	if err != nil {
-		return err
-	}
-	d.SetId("")
-	return nil
+	return err
 }
 
 func resourceBrightboxServerUpdate(
@@ -235,47 +756,713 @@ func resourceBrightboxServerUpdate(
 	client := meta.(*CompositeClient).ApiClient
 
 	log.Printf("[DEBUG] Server update called for %s", d.Id())
+	if d.HasChange("recreated_from") {
+		return rebuildServer(d, meta.(*CompositeClient))
+	}
+
 	server_opts := &brightbox.ServerOptions{
 		Id: d.Id(),
 	}
 
-	err := addUpdateableServerOptions(d, server_opts)
+	err := addUpdateableServerOptions(d, server_opts, meta.(*CompositeClient))
 	if err != nil {
 		return err
 	}
 
 	log.Printf("[DEBUG] Server update configuration: %#v", server_opts)
 
+	if d.HasChange("locked") {
+		if err := setServerLock(client, d.Id(), d.Get("locked").(bool)); err != nil {
+			return err
+		}
+	}
+
+	// The type can only be changed with a resize, which the update
+	// itself ignores
+	if server_opts.ServerType != "" {
+		err := resizeServer(client, d.Id(), server_opts.ServerType, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			return err
+		}
+		server_opts.ServerType = ""
+	}
+
+	// Join and leave only the groups that changed, so that groups the
+	// server joined outside Terraform since the last refresh are kept
+	if server_opts.ServerGroups != nil {
+		old, new := d.GetChange("server_groups")
+		err := updateServerGroupMembership(client, d.Id(), old.(*schema.Set), new.(*schema.Set))
+		if err != nil {
+			return err
+		}
+		server_opts.ServerGroups = nil
+	}
+
 	server, err := client.UpdateServer(server_opts)
 	if err != nil {
 		return fmt.Errorf("Error updating server: %s", err)
 	}
 
+	if d.HasChange("load_balancer") {
+		old, new := d.GetChange("load_balancer")
+		if old.(string) != "" {
+			err := removeServerFromLoadBalancer(client, d.Id(), old.(string))
+			if err != nil {
+				return err
+			}
+		}
+		if new.(string) != "" {
+			err := addServerToLoadBalancer(client, d.Id(), new.(string))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.HasChange("reboot_triggers") {
+		server, err = rebootServer(client, server, d.Timeout(schema.TimeoutUpdate))
+		if err != nil {
+			return err
+		}
+	}
+
 	return setServerAttributes(d, server)
 }
 
+// Replace the server with one built from a snapshot of it, as planned by
+// resourceBrightboxServerCustomizeDiff. The replacement is built before
+// the old server is destroyed, and the snapshot is removed either way.
+func rebuildServer(
+	d *schema.ResourceData,
+	meta *CompositeClient,
+) error {
+	client := meta.ApiClient
+	old_id := d.Id()
+	timeout := d.Timeout(schema.TimeoutUpdate)
+	deadline := time.Now().Add(timeout)
+
+	server, err := client.Server(old_id)
+	if err != nil {
+		return fmt.Errorf("Error retrieving server details: %s", err)
+	}
+	if server.Locked {
+		return fmt.Errorf("Server %s is locked and cannot be replaced. "+
+			"Set locked to false and apply before changing its size or zone", old_id)
+	}
+
+	// Keep the old server in state until it has been replaced
+	d.Partial(true)
+
+	snapshot, err := snapshotServer(client, old_id)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		log.Printf("[INFO] Removing snapshot %s", snapshot)
+		if err := client.DestroyImage(snapshot); err != nil {
+			log.Printf("[WARN] Unable to remove snapshot %s: %s", snapshot, err)
+		}
+	}()
+	err = waitForServerSnapshot(client, snapshot, old_id, timeout)
+	if err != nil {
+		return err
+	}
+
+	server_opts, err := rebuildServerOptions(d, meta, server, snapshot)
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] Building replacement for server %s from snapshot %s: %#v", old_id, snapshot, server_opts)
+	requested_zone := server_opts.Zone
+	new_server, err := createServerWithFallback(client, server_opts, map_from_string_list(d.Get("zone_fallback").([]interface{})))
+	if err != nil {
+		return fmt.Errorf("Error creating replacement for server %s: %s", old_id, err)
+	}
+	active_server, err := waitForServerAvailable(client, new_server.Id, timeout)
+	if err != nil {
+		log.Printf("[WARN] Removing replacement server %s", new_server.Id)
+		if err := destroyServer(client, new_server.Id, "", timeout); err != nil {
+			log.Printf("[WARN] Unable to remove replacement server %s: %s", new_server.Id, err)
+		}
+		return fmt.Errorf("Error waiting for replacement server %s to become available, server %s has been kept: %s",
+			new_server.Id, old_id, err)
+	}
+
+	old_load_balancer, _ := d.GetChange("load_balancer")
+	d.Partial(false)
+	d.SetId(active_server.Id)
+	d.Set("recreated_from", old_id)
+	setZoneRequested(d, requested_zone, server_opts.Zone)
+	err = destroyServer(client, old_id, old_load_balancer.(string), timeout)
+	if err != nil {
+		setServerAttributes(d, active_server)
+		return fmt.Errorf("Server %s was replaced by %s but destroying it failed: %s", old_id, active_server.Id, err)
+	}
+
+	if step, err := setupServer(d, client, active_server, deadline); err != nil {
+		setServerAttributes(d, active_server)
+		return fmt.Errorf("Server %s was replaced by %s but %s failed: %s. "+
+			"Apply again to complete the remaining steps", old_id, active_server.Id, step, err)
+	}
+
+	return setServerAttributes(d, active_server)
+}
+
+// Options for a server built to replace the given one. Settings that have
+// not changed are carried over from the old server.
+func rebuildServerOptions(
+	d *schema.ResourceData,
+	meta *CompositeClient,
+	server *brightbox.Server,
+	image string,
+) (*brightbox.ServerOptions, error) {
+	server_opts := &brightbox.ServerOptions{
+		Image: image,
+		Zone:  d.Get("zone").(string),
+	}
+	err := addUpdateableServerOptions(d, server_opts, meta)
+	if err != nil {
+		return nil, err
+	}
+	if server_opts.Name == nil {
+		server_opts.Name = &server.Name
+	}
+	if server_opts.UserData == nil && server.UserData != "" {
+		server_opts.UserData = &server.UserData
+	}
+	if server_opts.ServerGroups == nil {
+		server_opts.ServerGroups = map_from_string_set(d, "server_groups")
+	}
+	if server_opts.ServerType == "" {
+		if d.HasChange("ram") || d.HasChange("cores") {
+			server_opts.ServerType, err = serverTypeHandleFromSpec(d, meta.ApiClient)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			server_opts.ServerType = d.Get("type").(string)
+		}
+	}
+	return server_opts, nil
+}
+
+// Groups are joined before any are left, as a server can never be in
+// no groups at all
+func updateServerGroupMembership(
+	client *brightbox.Client,
+	server_id string,
+	old *schema.Set,
+	new *schema.Set,
+) error {
+	joining := map_from_string_list(new.Difference(old).List())
+	sort.Strings(joining)
+	for _, group := range joining {
+		log.Printf("[INFO] Adding Server %s to Server Group %s", server_id, group)
+		if _, err := client.AddServersToServerGroup(group, []string{server_id}); err != nil {
+			return fmt.Errorf("Error adding server %s to server group %s: %s", server_id, group, err)
+		}
+	}
+	leaving := map_from_string_list(old.Difference(new).List())
+	sort.Strings(leaving)
+	for _, group := range leaving {
+		log.Printf("[INFO] Removing Server %s from Server Group %s", server_id, group)
+		if _, err := client.RemoveServersFromServerGroup(group, []string{server_id}); err != nil {
+			return fmt.Errorf("Error removing server %s from server group %s: %s", server_id, group, err)
+		}
+	}
+	return nil
+}
+
+// Lock the server against deletion, or unlock it
+func setServerLock(
+	client *brightbox.Client,
+	server_id string,
+	locked bool,
+) error {
+	server := brightbox.Server{Id: server_id}
+	if locked {
+		log.Printf("[INFO] Locking Server %s", server_id)
+		if err := client.LockResource(server); err != nil {
+			return fmt.Errorf("Error locking server %s: %s", server_id, err)
+		}
+		return nil
+	}
+	log.Printf("[INFO] Unlocking Server %s", server_id)
+	if err := client.UnLockResource(server); err != nil {
+		return fmt.Errorf("Error unlocking server %s: %s", server_id, err)
+	}
+	return nil
+}
+
+func addServerToLoadBalancer(
+	client *brightbox.Client,
+	server_id string,
+	load_balancer_id string,
+) error {
+	log.Printf("[INFO] Adding Server %s to Load Balancer %s", server_id, load_balancer_id)
+	_, err := client.AddNodesToLoadBalancer(
+		load_balancer_id,
+		[]brightbox.LoadBalancerNode{{Node: server_id}},
+	)
+	if err != nil {
+		return fmt.Errorf("Error adding Server %s to Load Balancer %s: %s", server_id, load_balancer_id, err)
+	}
+	return nil
+}
+
+func removeServerFromLoadBalancer(
+	client *brightbox.Client,
+	server_id string,
+	load_balancer_id string,
+) error {
+	load_balancer, err := client.LoadBalancer(load_balancer_id)
+	if err != nil {
+		return fmt.Errorf("Error retrieving Load Balancer details: %s", err)
+	}
+	if !loadBalancerHasNode(load_balancer, server_id) {
+		log.Printf("[DEBUG] Server %s is not a node of Load Balancer %s", server_id, load_balancer_id)
+		return nil
+	}
+	log.Printf("[INFO] Removing Server %s from Load Balancer %s", server_id, load_balancer_id)
+	_, err = client.RemoveNodesFromLoadBalancer(
+		load_balancer_id,
+		[]brightbox.LoadBalancerNode{{Node: server_id}},
+	)
+	if err != nil {
+		return fmt.Errorf("Error removing Server %s from Load Balancer %s: %s", server_id, load_balancer_id, err)
+	}
+	return nil
+}
+
+func loadBalancerHasNode(load_balancer *brightbox.LoadBalancer, server_id string) bool {
+	for _, node := range load_balancer.Nodes {
+		if node.Id == server_id {
+			return true
+		}
+	}
+	return false
+}
+
+// Reflect whether the server is still a node of the configured load
+// balancer. Only that load balancer is checked, so membership managed by
+// a brightbox_load_balancer resource does not show up as a diff here.
+func setServerLoadBalancer(
+	d *schema.ResourceData,
+	client *brightbox.Client,
+) error {
+	load_balancer_id := d.Get("load_balancer").(string)
+	if load_balancer_id == "" {
+		return nil
+	}
+	load_balancer, err := client.LoadBalancer(load_balancer_id)
+	if err != nil {
+		if apierr, ok := err.(brightbox.ApiError); ok && apierr.StatusCode == http.StatusNotFound {
+			d.Set("load_balancer", "")
+			return nil
+		}
+		return fmt.Errorf("Error retrieving Load Balancer details: %s", err)
+	}
+	if load_balancer.Status == "deleted" || !loadBalancerHasNode(load_balancer, d.Id()) {
+		d.Set("load_balancer", "")
+	}
+	return nil
+}
+
+// A change of size or zone replaces the server. With snapshot_on_recreate
+// set, and the image unchanged, Update rebuilds the server from a
+// snapshot instead, recording the server being replaced.
+func resourceBrightboxServerCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	// MinItems is only checked against the configuration, so catch a
+	// list of groups that turns out to be empty once it is known. The
+	// API would otherwise ignore the empty list.
+	if d.NewValueKnown("server_groups") && d.Get("server_groups").(*schema.Set).Len() == 0 {
+		return fmt.Errorf("server_groups must contain at least one server group, a server cannot be left in no groups")
+	}
+	if allowed := meta.(*CompositeClient).AllowedServerGroups; len(allowed) > 0 && d.NewValueKnown("server_groups") {
+		groups := d.Get("server_groups").(*schema.Set)
+		if disallowed := disallowedServerGroups(groups, allowed); len(disallowed) > 0 {
+			return fmt.Errorf("server_groups %s are not in the provider's allowed_server_groups", strings.Join(disallowed, ", "))
+		}
+	}
+	if d.Id() == "" {
+		return nil
+	}
+	rebuild := d.Get("snapshot_on_recreate").(bool) && !d.HasChange("image")
+	var changed []string
+	for _, key := range []string{"ram", "cores", "zone"} {
+		if diffHasKey(d, key) {
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if !rebuild {
+		for _, key := range changed {
+			if err := d.ForceNew(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	log.Printf("[DEBUG] Server %s will be rebuilt from a snapshot", d.Id())
+	if err := d.SetNew("recreated_from", d.Id()); err != nil {
+		return err
+	}
+	computed := serverRebuildComputedKeys
+	if diffHasKey(d, "ram") || diffHasKey(d, "cores") {
+		computed = append([]string{"type"}, computed...)
+	}
+	for _, key := range computed {
+		if err := d.SetNewComputed(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasChange reads the configuration, so it also reports a change that a
+// DiffSuppressFunc has removed from the diff
+func diffHasKey(d *schema.ResourceDiff, key string) bool {
+	for _, changed := range d.GetChangedKeysPrefix(key) {
+		if changed == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Attributes that are only known once a rebuilt server exists
+var serverRebuildComputedKeys = []string{
+	"image_id", "status", "created_at", "started_at", "console_available",
+	"interface", "mac_address", "interfaces", "ipv6_address", "ipv4_address",
+	"ipv4_address_private", "public_ipv4", "public_ipv6", "egress_ip",
+	"hostname", "fqdn", "public_hostname", "ipv6_hostname", "zone_requested",
+}
+
+func disallowedServerGroups(groups *schema.Set, allowed []string) []string {
+	permitted := schema.NewSet(schema.HashString, nil)
+	for _, group := range allowed {
+		permitted.Add(group)
+	}
+	var disallowed []string
+	for _, group := range groups.Difference(permitted).List() {
+		disallowed = append(disallowed, group.(string))
+	}
+	sort.Strings(disallowed)
+	return disallowed
+}
+
+// Create the server, moving on to the next fallback zone each time the
+// API reports that a zone has no capacity for it. Any other error is
+// returned straight away.
+func createServerWithFallback(
+	client *brightbox.Client,
+	server_opts *brightbox.ServerOptions,
+	fallback_zones []string,
+) (*brightbox.Server, error) {
+	zones := append([]string{server_opts.Zone}, fallback_zones...)
+	var err error
+	for i, zone := range zones {
+		server_opts.Zone = zone
+		var server *brightbox.Server
+		server, err = client.CreateServer(server_opts)
+		if err == nil || !isCapacityError(err) {
+			return server, err
+		}
+		if i < len(zones)-1 {
+			log.Printf("[WARN] No capacity for server in zone %q, trying %s: %s", zone, zones[i+1], err)
+		}
+	}
+	return nil, err
+}
+
+func isCapacityError(err error) bool {
+	apierr, ok := err.(brightbox.ApiError)
+	if !ok {
+		return false
+	}
+	if strings.Contains(apierr.ErrorName, "capacity") {
+		return true
+	}
+	for _, message := range apierr.Errors {
+		if strings.Contains(strings.ToLower(message), "capacity") {
+			return true
+		}
+	}
+	return false
+}
+
+// A server built in one of its fallback zones stays there rather than
+// being replaced to move it into the configured zone. Only the zone that
+// was asked for when the server was built is ignored, so changing zone
+// to anywhere else still moves the server.
+func suppressFallbackZone(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" || d.Id() == "" {
+		return false
+	}
+	requested := d.Get("zone_requested").(string)
+	return requested != "" && new == requested
+}
+
+// Record the zone asked for when a fallback zone had to be used instead,
+// so that suppressFallbackZone can tell that drift from a change of zone.
+func setZoneRequested(d *schema.ResourceData, requested string, built string) {
+	if requested != built {
+		d.Set("zone_requested", requested)
+	} else {
+		d.Set("zone_requested", "")
+	}
+}
+
+// Ask for a snapshot of the server. The snapshot image exists from this
+// point on, so callers record or remove it before waiting for it with
+// waitForServerSnapshot.
+func snapshotServer(
+	client *brightbox.Client,
+	server_id string,
+) (string, error) {
+	log.Printf("[INFO] Snapshotting server %s", server_id)
+	image, err := client.SnapshotServer(server_id)
+	if err != nil {
+		return "", fmt.Errorf("Error snapshotting server %s: %s", server_id, err)
+	}
+	if image == nil {
+		return "", fmt.Errorf("Error snapshotting server %s: no snapshot image returned", server_id)
+	}
+	return image.Id, nil
+}
+
+func waitForServerSnapshot(
+	client *brightbox.Client,
+	image_id string,
+	server_id string,
+	timeout time.Duration,
+) error {
+	log.Printf("[INFO] Waiting for snapshot %s of server %s to become available", image_id, server_id)
+	stateConf := resource.StateChangeConf{
+		Pending:    []string{"creating"},
+		Target:     []string{"available"},
+		Refresh:    imageStateRefresh(client, image_id),
+		Timeout:    timeout,
+		Delay:      checkDelay,
+		MinTimeout: minimumRefreshWait,
+	}
+	_, err := stateConf.WaitForState()
+	if err != nil {
+		return fmt.Errorf("Error waiting for snapshot %s of server %s: %s", image_id, server_id, err)
+	}
+	return nil
+}
+
+func imageStateRefresh(client *brightbox.Client, imageID string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		image, err := client.Image(imageID)
+		if err != nil {
+			log.Printf("Error on Image State Refresh: %s", err)
+			return nil, "", err
+		}
+		return image, image.Status, nil
+	}
+}
+
+func rebootServer(
+	client *brightbox.Client,
+	server *brightbox.Server,
+	timeout time.Duration,
+) (*brightbox.Server, error) {
+	if server.Status != "active" {
+		log.Printf("[INFO] Server %s is %s, skipping reboot", server.Id, server.Status)
+		return server, nil
+	}
+	log.Printf("[INFO] Rebooting Server %s", server.Id)
+	err := client.RebootServer(server.Id)
+	if err != nil {
+		return nil, fmt.Errorf("Error rebooting server %s: %s", server.Id, err)
+	}
+	log.Printf("[INFO] Waiting for Server (%s) to restart", server.Id)
+	stateConf := resource.StateChangeConf{
+		Pending:    []string{"rebooting", "inactive"},
+		Target:     []string{"active"},
+		Refresh:    serverRebootRefresh(client, server.Id, server.StartedAt),
+		Timeout:    timeout,
+		Delay:      checkDelay,
+		MinTimeout: minimumRefreshWait,
+	}
+	active_server, err := stateConf.WaitForState()
+	if err != nil {
+		return nil, fmt.Errorf("Error waiting for server %s to reboot: %s", server.Id, err)
+	}
+	return active_server.(*brightbox.Server), nil
+}
+
+// The reboot request returns before the server goes down, so an active
+// server is only taken as rebooted once its started_at has moved on from
+// the time it had before the reboot. Until then it is "rebooting".
+func serverRebootRefresh(
+	client *brightbox.Client,
+	serverID string,
+	startedAt *time.Time,
+) resource.StateRefreshFunc {
+	refresh := serverStateRefresh(client, serverID)
+	return func() (interface{}, string, error) {
+		server, status, err := refresh()
+		if err != nil || status != "active" {
+			return server, status, err
+		}
+		restarted := server.(*brightbox.Server).StartedAt
+		if restarted == nil || (startedAt != nil && !restarted.After(*startedAt)) {
+			return server, "rebooting", nil
+		}
+		return server, status, nil
+	}
+}
+
+// Resize the server to a new type. The server has to be stopped for
+// the resize, so an active server is stopped first and started again
+// afterwards. An inactive server is left inactive.
+func resizeServer(
+	client *brightbox.Client,
+	server_id string,
+	handle string,
+	timeout time.Duration,
+) error {
+	server_type, err := client.ServerTypeByHandle(handle)
+	if err != nil {
+		return fmt.Errorf("Error retrieving server type %s: %s", handle, err)
+	}
+	server, err := client.Server(server_id)
+	if err != nil {
+		return fmt.Errorf("Error retrieving server details: %s", err)
+	}
+	was_active := server.Status == "active"
+	if was_active {
+		log.Printf("[INFO] Stopping Server %s to resize it", server_id)
+		if err := client.StopServer(server_id); err != nil {
+			return fmt.Errorf("Error stopping server %s: %s", server_id, err)
+		}
+		if err := waitForServerStatus(client, server_id, "active", "inactive", timeout); err != nil {
+			return err
+		}
+	}
+	log.Printf("[INFO] Resizing Server %s to %s", server_id, server_type.Handle)
+	_, err = client.MakeApiRequest(
+		"POST",
+		"/1.0/servers/"+server_id+"/resize",
+		map[string]string{"server_type": server_type.Id},
+		nil,
+	)
+	if err != nil {
+		err = fmt.Errorf("Error resizing server %s to %s: %s", server_id, server_type.Handle, err)
+	}
+	if was_active {
+		log.Printf("[INFO] Starting Server %s", server_id)
+		if start_err := client.StartServer(server_id); start_err != nil {
+			if err != nil {
+				log.Printf("[WARN] Unable to restart Server %s: %s", server_id, start_err)
+				return err
+			}
+			return fmt.Errorf("Error starting server %s: %s", server_id, start_err)
+		}
+		if wait_err := waitForServerStatus(client, server_id, "inactive", "active", timeout); err == nil {
+			err = wait_err
+		}
+	}
+	return err
+}
+
+func waitForServerStatus(
+	client *brightbox.Client,
+	server_id string,
+	pending string,
+	target string,
+	timeout time.Duration,
+) error {
+	log.Printf("[INFO] Waiting for Server (%s) to become %s", server_id, target)
+	stateConf := resource.StateChangeConf{
+		Pending:    []string{pending},
+		Target:     []string{target},
+		Refresh:    serverStateRefresh(client, server_id),
+		Timeout:    timeout,
+		Delay:      checkDelay,
+		MinTimeout: minimumRefreshWait,
+	}
+	_, err := stateConf.WaitForState()
+	if err != nil {
+		return fmt.Errorf("Error waiting for server %s to become %s: %s", server_id, target, err)
+	}
+	return nil
+}
+
 func addUpdateableServerOptions(
 	d *schema.ResourceData,
 	opts *brightbox.ServerOptions,
+	client *CompositeClient,
 ) error {
 	assign_string(d, &opts.Name, "name")
+	if d.HasChange("type") {
+		opts.ServerType = d.Get("type").(string)
+	}
+	// A new server is given the full list of groups. Update clears this
+	// and joins and leaves groups in updateServerGroupMembership instead.
 	assign_string_set(d, &opts.ServerGroups, "server_groups")
-	if d.HasChange("user_data") {
+	userdata_limit := client.UserDataLimit
+	if d.HasChange("user_data") || d.HasChange("user_data_parts") || d.HasChange("user_data_orbit_object") || d.HasChange("user_data_gzip") {
+		compress := d.Get("user_data_gzip").(bool)
 		encoded_userdata := ""
+		var content []byte
 		if user_data, ok := d.GetOk("user_data"); ok {
 			log.Printf("[DEBUG] UserData to encode: %s", user_data.(string))
+			if isBase64Encoded(user_data.(string)) {
+				log.Printf("[WARN] user_data is already base64 encoded, passing through")
+			}
 			encoded_userdata = base64Encode(user_data.(string))
+			content, _ = base64.StdEncoding.DecodeString(encoded_userdata)
 		} else if user_data, ok := d.GetOk("user_data_base64"); ok {
 			log.Printf("[DEBUG] Encoded Userdata found, passing through")
 			encoded_userdata = user_data.(string)
+		} else if parts, ok := d.GetOk("user_data_parts"); ok {
+			log.Printf("[DEBUG] Assembling UserData from %d parts", len(parts.([]interface{})))
+			limit := userdata_limit
+			if compress {
+				// Compress the parts whatever their size
+				limit = 0
+			}
+			encoded, err := encodeUserDataParts(parts.([]interface{}), limit)
+			if err != nil {
+				return fmt.Errorf("Error assembling user_data_parts: %s", err)
+			}
+			encoded_userdata = encoded
+		} else if path, ok := d.GetOk("user_data_orbit_object"); ok {
+			log.Printf("[DEBUG] Fetching UserData from Orbit object %s", path.(string))
+			var err error
+			content, err = orbitObjectContent(client.OrbitClient, path.(string))
+			if err != nil {
+				return err
+			}
+			encoded_userdata = base64.StdEncoding.EncodeToString(content)
+		}
+		// The parts compress themselves and user_data_base64 is sent as given
+		if compress && content != nil {
+			log.Printf("[DEBUG] Compressing UserData")
+			encoded, err := gzipUserData(content)
+			if err != nil {
+				return fmt.Errorf("Error compressing user_data: %s", err)
+			}
+			encoded_userdata = encoded
 		}
 		if encoded_userdata == "" {
 			// Nothing found, nothing to do
-		} else if len(encoded_userdata) > userdata_size_limit {
+		} else if len(encoded_userdata) > userdata_limit {
+			hint := ""
+			if !compress && content != nil {
+				hint = ". Set user_data_gzip to compress it"
+			}
 			return fmt.Errorf(
-				"The supplied user_data contains %d bytes after encoding, this exeeds the limit of %d bytes",
+				"The supplied user_data contains %d bytes after encoding, this exeeds the limit of %d bytes%s",
 				len(encoded_userdata),
-				userdata_size_limit,
+				userdata_limit,
+				hint,
 			)
 		} else {
 			opts.UserData = &encoded_userdata
@@ -288,28 +1475,59 @@ func setServerAttributes(
 	d *schema.ResourceData,
 	server *brightbox.Server,
 ) error {
-	d.Set("image", server.Image.Id)
+	// Keep the configured image, which may be a name or differ from
+	// the snapshot a replacement server was built from.
+	if d.Get("image").(string) == "" {
+		d.Set("image", server.Image.Id)
+	}
+	d.Set("image_id", server.Image.Id)
 	d.Set("name", server.Name)
-	d.Set("type", server.ServerType.Handle)
+	setServerTypeAttributes(d, server)
 	d.Set("zone", server.Zone.Handle)
 	d.Set("status", server.Status)
+	d.Set("created_at", optionalTimeString(server.CreatedAt))
+	d.Set("started_at", optionalTimeString(server.StartedAt))
+	// The graphical console can only be activated on a running server
+	d.Set("console_available", server.Status == "active")
 	d.Set("locked", server.Locked)
 	d.Set("hostname", server.Hostname)
 	d.Set("username", server.Image.Username)
+	d.Set("os_family", imageOSFamily(&server.Image))
+	d.Set("fqdn", server.Fqdn)
 
 	if len(server.Interfaces) > 0 {
 		server_interface := server.Interfaces[0]
 		d.Set("interface", server_interface.Id)
+		d.Set("mac_address", server_interface.MacAddress)
 		d.Set("ipv4_address_private", server_interface.IPv4Address)
-		d.Set("fqdn", server.Fqdn)
 		d.Set("ipv6_address", server_interface.IPv6Address)
-		d.Set("ipv6_hostname", "ipv6."+server.Fqdn)
+		if server_interface.IPv6Address != "" && server.Fqdn != "" {
+			d.Set("ipv6_hostname", "ipv6."+server.Fqdn)
+		} else {
+			d.Set("ipv6_hostname", "")
+		}
+	} else {
+		d.Set("interface", "")
+		d.Set("mac_address", "")
+		d.Set("ipv4_address_private", "")
+		d.Set("ipv6_address", "")
+		d.Set("ipv6_hostname", "")
 	}
 
+	d.Set("interfaces", flattenServerInterfaces(server.Interfaces))
+
 	if len(server.CloudIPs) > 0 {
 		setPrimaryCloudIp(d, &server.CloudIPs[0])
+		d.Set("public_ipv4", cloudIpPublicIPv4(&server.CloudIPs[0]))
+		d.Set("public_ipv6", server.CloudIPs[0].PublicIPv6)
+	} else {
+		d.Set("ipv4_address", "")
+		d.Set("public_hostname", "")
+		d.Set("public_ipv4", "")
+		d.Set("public_ipv6", "")
 	}
 
+	d.Set("egress_ip", serverEgressIp(server))
 	d.Set("server_groups", schema.NewSet(schema.HashString, flattenServerGroups(server.ServerGroups)))
 
 	setUserDataDetails(d, server.UserData)
@@ -326,9 +1544,44 @@ func flattenServerGroups(list []brightbox.ServerGroup) []interface{} {
 	return srvGrpIds
 }
 
+func flattenServerInterfaces(list []brightbox.ServerInterface) []interface{} {
+	interfaces := make([]interface{}, len(list))
+	for i, server_interface := range list {
+		interfaces[i] = map[string]interface{}{
+			"id":           server_interface.Id,
+			"mac_address":  server_interface.MacAddress,
+			"ipv4_address": server_interface.IPv4Address,
+			"ipv6_address": server_interface.IPv6Address,
+		}
+	}
+	return interfaces
+}
+
 func setUserDataDetails(d *schema.ResourceData, base64_userdata string) {
+	if d.Get("expose_user_data").(bool) {
+		d.Set("user_data_plaintext", decodeUserData(base64_userdata))
+	} else {
+		d.Set("user_data_plaintext", "")
+	}
+	if _, ok := d.GetOk("user_data_orbit_object"); ok {
+		log.Printf("[DEBUG] user data taken from an Orbit object, leaving it unchecked")
+		return
+	}
 	if len(base64_userdata) <= 0 {
-		log.Printf("[DEBUG] No user data found, skipping set")
+		log.Printf("[DEBUG] No user data found, clearing any configured user data")
+		d.Set("user_data", "")
+		d.Set("user_data_base64", "")
+		d.Set("user_data_parts", nil)
+		return
+	}
+	if parts, ok := d.GetOk("user_data_parts"); ok {
+		// The parts can't be recovered from the document, so compare
+		// it with the rendered parts and clear them if it has changed.
+		document, err := renderUserDataParts(parts.([]interface{}))
+		if err != nil || document != decodeUserData(base64_userdata) {
+			log.Printf("[WARN] user data no longer matches user_data_parts, marking for update")
+			d.Set("user_data_parts", nil)
+		}
 		return
 	}
 	_, b64 := d.GetOk("user_data_base64")
@@ -337,33 +1590,129 @@ func setUserDataDetails(d *schema.ResourceData, base64_userdata string) {
 		d.Set("user_data_base64", base64_userdata)
 	} else {
 		log.Printf("[DEBUG] decrypted user_data requested, setting user_data")
-		d.Set("user_data", userDataHashSum(base64_userdata))
+		d.Set("user_data", decodedUserDataHashSum(base64_userdata))
+	}
+}
+
+// Older API versions only give the IPv4 address as public_ip
+func cloudIpPublicIPv4(cloud_ip *brightbox.CloudIP) string {
+	if cloud_ip.PublicIPv4 != "" {
+		return cloud_ip.PublicIPv4
+	}
+	return cloud_ip.PublicIP
+}
+
+// Outbound IPv4 traffic is translated to the first Cloud IP mapped to
+// the server, the same one reported as ipv4_address. Without a Cloud IP
+// Brightbox NATs outbound IPv4 through a shared address the API doesn't
+// report, so there is no address to give.
+func serverEgressIp(server *brightbox.Server) string {
+	for _, cloud_ip := range server.CloudIPs {
+		if public_ipv4 := cloudIpPublicIPv4(&cloud_ip); public_ipv4 != "" {
+			return public_ipv4
+		}
 	}
+	return ""
+}
+
+// Brightbox images carry no OS field, so Windows images are recognised
+// by their name or licence.
+func imageOSFamily(image *brightbox.Image) string {
+	for _, field := range []string{image.Name, image.LicenceName, image.Description} {
+		if strings.Contains(strings.ToLower(field), "windows") {
+			return "windows"
+		}
+	}
+	return "linux"
 }
 
 func setConnectionDetails(d *schema.ResourceData) {
-	var preferredSSHAddress string
+	user := connectionUser(d)
+	d.Set("connection_user", user)
+
+	var preferredAddress string
 	if attr, ok := d.GetOk("public_hostname"); ok {
-		preferredSSHAddress = attr.(string)
+		preferredAddress = attr.(string)
 	} else if attr, ok := d.GetOk("ipv6_hostname"); ok {
-		preferredSSHAddress = attr.(string)
+		preferredAddress = attr.(string)
 	} else if attr, ok := d.GetOk("fqdn"); ok {
-		preferredSSHAddress = attr.(string)
+		preferredAddress = attr.(string)
 	}
 
-	if preferredSSHAddress != "" {
+	if preferredAddress != "" {
 		connection_details := map[string]string{
 			"type": "ssh",
-			"host": preferredSSHAddress,
+			"host": preferredAddress,
 		}
-		if attr, ok := d.GetOk("username"); ok {
-			connection_details["user"] = attr.(string)
+		if d.Get("os_family").(string) == "windows" {
+			connection_details["type"] = "winrm"
+			connection_details["port"] = "5985"
+			connection_details["https"] = "false"
 		}
+		overrideConnectionDetails(d, connection_details)
+		connection_details["user"] = user
 		d.SetConnInfo(connection_details)
 	}
 }
 
+// The user provisioners connect as: the connection_settings user, then
+// the image's username, then the default for the connection type.
+func connectionUser(d *schema.ResourceData) string {
+	if user := d.Get("connection_settings.0.user").(string); user != "" {
+		return user
+	}
+	if user := d.Get("username").(string); user != "" {
+		return user
+	}
+	conn_type := d.Get("connection_settings.0.type").(string)
+	if conn_type == "winrm" || (conn_type == "" && d.Get("os_family").(string) == "windows") {
+		return "Administrator"
+	}
+	return "root"
+}
+
+// Apply any connection_settings over the detected connection details.
+// Port and https only apply to the protocol they were given for.
+func overrideConnectionDetails(d *schema.ResourceData, connection_details map[string]string) {
+	settings, ok := d.GetOk("connection_settings.0")
+	if !ok {
+		return
+	}
+	override := settings.(map[string]interface{})
+	if conn_type := override["type"].(string); conn_type != "" && conn_type != connection_details["type"] {
+		connection_details["type"] = conn_type
+		delete(connection_details, "port")
+		delete(connection_details, "https")
+	}
+	port := override["port"].(int)
+	if connection_details["type"] == "winrm" {
+		https := override["https"].(bool)
+		connection_details["https"] = strconv.FormatBool(https)
+		if port == 0 && https {
+			port = 5986
+		} else if port == 0 {
+			port = 5985
+		}
+	}
+	if port != 0 {
+		connection_details["port"] = strconv.Itoa(port)
+	}
+}
+
+// A server that fails to build or boot stays failed, so stop waiting
+// as soon as it is seen rather than running into the timeout.
 func serverStateRefresh(client *brightbox.Client, serverID string) resource.StateRefreshFunc {
+	refresh := serverStatusRefresh(client, serverID)
+	return func() (interface{}, string, error) {
+		server, status, err := refresh()
+		if err == nil && status == "failed" {
+			return server, status, fmt.Errorf("Server %s is in the %s state", serverID, status)
+		}
+		return server, status, err
+	}
+}
+
+func serverStatusRefresh(client *brightbox.Client, serverID string) resource.StateRefreshFunc {
 	return func() (interface{}, string, error) {
 		server, err := client.Server(serverID)
 		if err != nil {
@@ -31,6 +31,16 @@ func resourceBrightboxServerGroup() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+
+			"default": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+
+			"firewall_policy": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 		},
 	}
 }
@@ -140,6 +150,14 @@ func setServerGroupAttributes(
 ) error {
 	d.Set("name", server_group.Name)
 	d.Set("description", server_group.Description)
+	d.Set("default", server_group.Default)
+	// The policy is managed by brightbox_firewall_policy, which names
+	// the group it applies to
+	if server_group.FirewallPolicy != nil {
+		d.Set("firewall_policy", server_group.FirewallPolicy.Id)
+	} else {
+		d.Set("firewall_policy", "")
+	}
 	return nil
 }
 
@@ -180,3 +180,26 @@ resource "brightbox_server_group" "foobar" {
 	description = ""
 }
 `
+
+func TestSetServerGroupAttributes_firewallPolicy(t *testing.T) {
+	d := resourceBrightboxServerGroup().Data(nil)
+	server_group := &brightbox.ServerGroup{
+		Id:             "grp-12345",
+		Name:           "web",
+		Description:    "Web servers",
+		FirewallPolicy: &brightbox.FirewallPolicy{Id: "fwp-12345"},
+	}
+	setServerGroupAttributes(d, server_group)
+	if got := d.Get("firewall_policy").(string); got != "fwp-12345" {
+		t.Errorf("Got firewall_policy %q, expected fwp-12345", got)
+	}
+	if got := d.Get("description").(string); got != "Web servers" {
+		t.Errorf("Got description %q", got)
+	}
+
+	server_group.FirewallPolicy = nil
+	setServerGroupAttributes(d, server_group)
+	if got := d.Get("firewall_policy").(string); got != "" {
+		t.Errorf("Expected firewall_policy to be cleared, got %q", got)
+	}
+}
@@ -0,0 +1,193 @@
+package brightbox
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+)
+
+func resourceBrightboxServerSnapshot() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceBrightboxServerSnapshotCreate,
+		Read:   resourceBrightboxServerSnapshotRead,
+		Update: resourceBrightboxServerSnapshotUpdate,
+		Delete: resourceBrightboxServerSnapshotDelete,
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(defaultTimeout),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"server_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(serverIdRe, "must be a valid server ID"),
+			},
+
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"description": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"size": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+
+			"arch": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+
+			"created_at": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// The fields of a snapshot image that can be changed
+type imageOptions struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+func resourceBrightboxServerSnapshotCreate(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	server_id := d.Get("server_id").(string)
+	image_id, err := snapshotServer(client, server_id)
+	if err != nil {
+		return err
+	}
+	d.SetId(image_id)
+
+	err = waitForServerSnapshot(client, image_id, server_id, meta.(*CompositeClient).timeout(d, schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
+
+	image_opts := &imageOptions{}
+	assign_string(d, &image_opts.Name, "name")
+	assign_string(d, &image_opts.Description, "description")
+	if image_opts.Name != nil || image_opts.Description != nil {
+		image, err := updateImage(client, image_id, image_opts)
+		if err != nil {
+			return err
+		}
+		return setServerSnapshotAttributes(d, image)
+	}
+
+	return resourceBrightboxServerSnapshotRead(d, meta)
+}
+
+func resourceBrightboxServerSnapshotRead(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	image, err := client.Image(d.Id())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "missing_resource:") {
+			log.Printf("[WARN] Server snapshot not found, removing from state: %s", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return fmt.Errorf("Error retrieving server snapshot details: %s", err)
+	}
+	if image.Status == "deleted" || image.Status == "deleting" {
+		log.Printf("[WARN] Server snapshot %s is %s, removing from state", d.Id(), image.Status)
+		d.SetId("")
+		return nil
+	}
+
+	return setServerSnapshotAttributes(d, image)
+}
+
+func resourceBrightboxServerSnapshotUpdate(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	image_opts := &imageOptions{}
+	assign_string(d, &image_opts.Name, "name")
+	assign_string(d, &image_opts.Description, "description")
+	image, err := updateImage(client, d.Id(), image_opts)
+	if err != nil {
+		return err
+	}
+
+	return setServerSnapshotAttributes(d, image)
+}
+
+func resourceBrightboxServerSnapshotDelete(
+	d *schema.ResourceData,
+	meta interface{},
+) error {
+	client := meta.(*CompositeClient).ApiClient
+
+	log.Printf("[INFO] Deleting Server snapshot %s", d.Id())
+	err := client.DestroyImage(d.Id())
+	if err != nil {
+		return fmt.Errorf("Error deleting server snapshot (%s): %s", d.Id(), err)
+	}
+	return nil
+}
+
+// gobrightbox has no image update call, so make the request directly.
+func updateImage(
+	client *brightbox.Client,
+	image_id string,
+	opts *imageOptions,
+) (*brightbox.Image, error) {
+	log.Printf("[DEBUG] Image update configuration: %#v", opts)
+	image := new(brightbox.Image)
+	_, err := client.MakeApiRequest("PUT", "/1.0/images/"+image_id, opts, image)
+	if err != nil {
+		return nil, fmt.Errorf("Error updating server snapshot (%s): %s", image_id, err)
+	}
+	return image, nil
+}
+
+func setServerSnapshotAttributes(
+	d *schema.ResourceData,
+	image *brightbox.Image,
+) error {
+	d.Set("name", image.Name)
+	d.Set("description", image.Description)
+	d.Set("status", image.Status)
+	d.Set("size", image.VirtualSize)
+	d.Set("arch", image.Arch)
+	d.Set("created_at", image.CreatedAt.Format(time.RFC3339))
+	if serverIdRe.MatchString(image.Source) {
+		d.Set("server_id", image.Source)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package brightbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestAccBrightboxServerSnapshot_Basic(t *testing.T) {
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerSnapshotDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxServerSnapshotConfig_basic(rInt, "nightly"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrPair(
+						"brightbox_server_snapshot.foobar", "server_id",
+						"brightbox_server.foobar", "id"),
+					resource.TestCheckResourceAttr(
+						"brightbox_server_snapshot.foobar", "status", "available"),
+					resource.TestCheckResourceAttr(
+						"brightbox_server_snapshot.foobar", "name", fmt.Sprintf("foo-%d nightly", rInt)),
+					resource.TestCheckResourceAttrSet(
+						"brightbox_server_snapshot.foobar", "created_at"),
+				),
+			},
+			{
+				Config: testAccCheckBrightboxServerSnapshotConfig_basic(rInt, "weekly"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr(
+						"brightbox_server_snapshot.foobar", "name", fmt.Sprintf("foo-%d weekly", rInt)),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxServerSnapshotDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*CompositeClient).ApiClient
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "brightbox_server_snapshot" {
+			continue
+		}
+
+		image, err := client.Image(rs.Primary.ID)
+		if err != nil {
+			apierror := err.(brightbox.ApiError)
+			if apierror.StatusCode != 404 {
+				return fmt.Errorf(
+					"Error waiting for server snapshot %s to be destroyed: %s",
+					rs.Primary.ID, err)
+			}
+			continue
+		}
+		if image.Status != "deleted" && image.Status != "deleting" {
+			return fmt.Errorf("Server snapshot %s still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+func TestResourceBrightboxServerSnapshotRead(t *testing.T) {
+	status := "available"
+	var update map[string]string
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.0/images/img-12345" {
+			http.NotFound(w, r)
+			return
+		}
+		name := "Snapshot of srv-12345"
+		if r.Method == "PUT" {
+			json.NewDecoder(r.Body).Decode(&update)
+			name = update["name"]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"img-12345","name":%q,"status":%q,"source":"srv-12345",
+			"arch":"x86_64","virtual_size":20480,"created_at":"2026-01-02T03:04:05Z"}`, name, status)
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	d := resourceBrightboxServerSnapshot().Data(nil)
+	d.SetId("img-12345")
+	if err := resourceBrightboxServerSnapshotRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("server_id").(string); got != "srv-12345" {
+		t.Errorf("Got server_id %q, expected srv-12345", got)
+	}
+	if got := d.Get("size").(int); got != 20480 {
+		t.Errorf("Got size %d, expected 20480", got)
+	}
+	if got := d.Get("created_at").(string); got != "2026-01-02T03:04:05Z" {
+		t.Errorf("Got created_at %q", got)
+	}
+
+	name := "nightly"
+	image, err := updateImage(client, "img-12345", &imageOptions{Name: &name})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if image.Name != "nightly" || update["name"] != "nightly" {
+		t.Errorf("Expected the name to be sent, got %v", update)
+	}
+	if _, ok := update["description"]; ok {
+		t.Errorf("Expected an unchanged description to be left out, got %v", update)
+	}
+
+	status = "deleted"
+	if err := resourceBrightboxServerSnapshotRead(d, meta); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if d.Id() != "" {
+		t.Errorf("Expected a deleted snapshot to be removed from state")
+	}
+}
+
+func testAccCheckBrightboxServerSnapshotConfig_basic(rInt int, schedule string) string {
+	return fmt.Sprintf(`
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "foo-%d"
+	type = "1gb.ssd"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+}
+
+resource "brightbox_server_snapshot" "foobar" {
+	server_id = "${brightbox_server.foobar.id}"
+	name = "${brightbox_server.foobar.name} %s"
+}
+
+%s%s`, rInt, schedule, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
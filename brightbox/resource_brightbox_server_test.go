@@ -1,13 +1,21 @@
 package brightbox
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 )
 
@@ -336,6 +344,1248 @@ func TestAccBrightboxServer_UpdateUserData(t *testing.T) {
 	})
 }
 
+func TestAccBrightboxServer_RebootTriggers(t *testing.T) {
+	var afterCreate, afterUpdate brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxServerConfig_reboot_triggers(rInt, "1"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &afterCreate),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "reboot_triggers.revision", "1"),
+				),
+			},
+			{
+				Config: testAccCheckBrightboxServerConfig_reboot_triggers(rInt, "2"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &afterUpdate),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "reboot_triggers.revision", "2"),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "status", "active"),
+					testAccCheckBrightboxServerRecreated(
+						t, &afterCreate, &afterUpdate),
+					testAccCheckBrightboxServerRebooted(
+						&afterCreate, &afterUpdate),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBrightboxServer_RamAndCores(t *testing.T) {
+	var server brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxServerConfig_ram_and_cores(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &server),
+					testAccCheckBrightboxServerAttributes(&server),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "type", "1gb.ssd"),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "ram", "1024"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBrightboxServer_LoadBalancer(t *testing.T) {
+	var server brightbox.Server
+	var load_balancer brightbox.LoadBalancer
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxLoadBalancerAndServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxServerConfig_load_balancer(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &server),
+					testAccCheckBrightboxLoadBalancerExists("brightbox_load_balancer.foobar", &load_balancer),
+					resource.TestCheckResourceAttrPair(
+						"brightbox_server.foobar", "load_balancer",
+						"brightbox_load_balancer.foobar", "id"),
+					testAccCheckBrightboxLoadBalancerHasNode(&load_balancer, &server),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxLoadBalancerHasNode(load_balancer *brightbox.LoadBalancer, server *brightbox.Server) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if !loadBalancerHasNode(load_balancer, server.Id) {
+			return fmt.Errorf("Server %s is not a node of Load Balancer %s", server.Id, load_balancer.Id)
+		}
+		return nil
+	}
+}
+
+func TestAccBrightboxServer_UserDataParts(t *testing.T) {
+	var server brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxServerConfig_user_data_parts(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &server),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "user_data_parts.#", "2"),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "user_data_parts.1.content_type", "text/x-shellscript"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBrightboxServer_SnapshotOnRecreate(t *testing.T) {
+	var afterCreate, afterUpdate brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxServerConfig_snapshot_on_recreate(rInt, "gb1-a"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &afterCreate),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "recreated_from", ""),
+				),
+			},
+			{
+				Config: testAccCheckBrightboxServerConfig_snapshot_on_recreate(rInt, "gb1-b"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &afterUpdate),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "zone", "gb1-b"),
+					testAccCheckBrightboxServerRecreatedFrom(&afterCreate, &afterUpdate),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBrightboxServer_Resize(t *testing.T) {
+	var afterCreate, afterUpdate brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxServerConfig_resize(rInt, "1gb.ssd"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &afterCreate),
+				),
+			},
+			{
+				Config: testAccCheckBrightboxServerConfig_resize(rInt, "2gb.ssd"),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &afterUpdate),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "type", "2gb.ssd"),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "status", "active"),
+					func(s *terraform.State) error {
+						if afterCreate.Id != afterUpdate.Id {
+							return fmt.Errorf("Expected the server to be resized in place, got new server %s", afterUpdate.Id)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckBrightboxServerRecreatedFrom(before, after *brightbox.Server) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if before.Id == after.Id {
+			return fmt.Errorf("Expected a new server, got the same id %s", after.Id)
+		}
+		if err := resource.TestCheckResourceAttr(
+			"brightbox_server.foobar", "recreated_from", before.Id)(s); err != nil {
+			return err
+		}
+		if after.Image.Id == before.Image.Id {
+			return fmt.Errorf("Expected the new server to be built from a snapshot, got image %s", after.Image.Id)
+		}
+		return nil
+	}
+}
+
+func TestRebuildServerOptions(t *testing.T) {
+	d := resourceBrightboxServer().Data(&terraform.InstanceState{
+		ID: "srv-old01",
+		Attributes: map[string]string{
+			"type":            "1gb.ssd",
+			"zone":            "gb1-b",
+			"server_groups.#": "1",
+			fmt.Sprintf("server_groups.%d", schema.HashString("grp-aaaaa")): "grp-aaaaa",
+		},
+	})
+	server := &brightbox.Server{
+		Id:       "srv-old01",
+		Name:     "web",
+		UserData: "dXNlcmRhdGE=",
+	}
+	opts, err := rebuildServerOptions(d, &CompositeClient{}, server, "img-snap1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if opts.Image != "img-snap1" {
+		t.Errorf("Expected image img-snap1, got %q", opts.Image)
+	}
+	if opts.Name == nil || *opts.Name != "web" {
+		t.Errorf("Expected the name to be carried over, got %v", opts.Name)
+	}
+	if opts.UserData == nil || *opts.UserData != server.UserData {
+		t.Errorf("Expected the user data to be carried over, got %v", opts.UserData)
+	}
+	if opts.ServerType != "1gb.ssd" || opts.Zone != "gb1-b" {
+		t.Errorf("Expected type 1gb.ssd in gb1-b, got %q in %q", opts.ServerType, opts.Zone)
+	}
+	if !reflect.DeepEqual(opts.ServerGroups, []string{"grp-aaaaa"}) {
+		t.Errorf("Expected server groups [grp-aaaaa], got %v", opts.ServerGroups)
+	}
+}
+
+func TestAccBrightboxServer_ImageName(t *testing.T) {
+	var server brightbox.Server
+	rInt := acctest.RandInt()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckBrightboxServerDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCheckBrightboxServerConfig_image_name(rInt),
+				Check: resource.ComposeTestCheckFunc(
+					testAccCheckBrightboxServerExists("brightbox_server.foobar", &server),
+					testAccCheckBrightboxServerAttributes(&server),
+					resource.TestCheckResourceAttr(
+						"brightbox_server.foobar", "image", "^Blank Disk Image$"),
+					resource.TestMatchResourceAttr(
+						"brightbox_server.foobar", "image_id", imageRe),
+				),
+			},
+		},
+	})
+}
+
+func TestFindServerTypeBySpec(t *testing.T) {
+	server_types := []brightbox.ServerType{
+		{Handle: "1gb.ssd", Status: "available", Ram: 1024, Cores: 1},
+		{Handle: "2gb.ssd", Status: "available", Ram: 2048, Cores: 1},
+		{Handle: "2gb.ssd-high-cpu", Status: "available", Ram: 2048, Cores: 2},
+		{Handle: "4gb.ssd", Status: "deprecated", Ram: 4096, Cores: 2},
+	}
+	var specTests = []struct {
+		name   string
+		ram    int
+		cores  int
+		handle string
+	}{
+		{"RAM only", 1024, 0, "1gb.ssd"},
+		{"RAM and cores", 2048, 2, "2gb.ssd-high-cpu"},
+		{"Ambiguous RAM", 2048, 0, ""},
+		{"Deprecated type", 4096, 2, ""},
+		{"No match", 8192, 4, ""},
+	}
+	for _, example := range specTests {
+		t.Run(
+			example.name,
+			func(t *testing.T) {
+				server_type, err := findServerTypeBySpec(server_types, example.ram, example.cores)
+				if example.handle == "" {
+					if err == nil {
+						t.Errorf("Expected an error, but got %s", server_type.Handle)
+					}
+				} else if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				} else if server_type.Handle != example.handle {
+					t.Errorf("Got %s, expected %s", server_type.Handle, example.handle)
+				}
+			},
+		)
+	}
+}
+
+func TestSetServerAttributes_interfaces(t *testing.T) {
+	var interfaceTests = []struct {
+		name         string
+		interfaces   []brightbox.ServerInterface
+		iface        string
+		mac          string
+		ipv6Hostname string
+	}{
+		{
+			name: "No interfaces",
+		},
+		{
+			name: "IPv4 only interface",
+			interfaces: []brightbox.ServerInterface{
+				{Id: "int-aaaaa", IPv4Address: "10.0.0.1"},
+			},
+			iface: "int-aaaaa",
+		},
+		{
+			name: "Multiple interfaces",
+			interfaces: []brightbox.ServerInterface{
+				{Id: "int-aaaaa", MacAddress: "02:24:19:00:00:01", IPv4Address: "10.0.0.1", IPv6Address: "2a02:1348::1"},
+				{Id: "int-bbbbb", MacAddress: "02:24:19:00:00:02", IPv4Address: "10.0.0.2", IPv6Address: "2a02:1348::2"},
+			},
+			iface:        "int-aaaaa",
+			mac:          "02:24:19:00:00:01",
+			ipv6Hostname: "ipv6.srv-12345.gb1.brightbox.com",
+		},
+	}
+	for _, example := range interfaceTests {
+		t.Run(
+			example.name,
+			func(t *testing.T) {
+				d := resourceBrightboxServer().Data(nil)
+				server := &brightbox.Server{
+					Id:         "srv-12345",
+					Fqdn:       "srv-12345.gb1.brightbox.com",
+					Interfaces: example.interfaces,
+				}
+				if err := setServerAttributes(d, server); err != nil {
+					t.Fatalf("err: %s", err)
+				}
+				if got := d.Get("fqdn").(string); got != server.Fqdn {
+					t.Errorf("Got fqdn %q, expected %q", got, server.Fqdn)
+				}
+				if got := d.Get("interface").(string); got != example.iface {
+					t.Errorf("Got interface %q, expected %q", got, example.iface)
+				}
+				if got := d.Get("mac_address").(string); got != example.mac {
+					t.Errorf("Got mac_address %q, expected %q", got, example.mac)
+				}
+				if got := d.Get("ipv6_hostname").(string); got != example.ipv6Hostname {
+					t.Errorf("Got ipv6_hostname %q, expected %q", got, example.ipv6Hostname)
+				}
+				if got := d.Get("interfaces.#").(int); got != len(example.interfaces) {
+					t.Errorf("Got %d interfaces, expected %d", got, len(example.interfaces))
+				}
+				for i, server_interface := range example.interfaces {
+					prefix := fmt.Sprintf("interfaces.%d.", i)
+					if got := d.Get(prefix + "id").(string); got != server_interface.Id {
+						t.Errorf("Got %sid %q, expected %q", prefix, got, server_interface.Id)
+					}
+					if got := d.Get(prefix + "ipv4_address").(string); got != server_interface.IPv4Address {
+						t.Errorf("Got %sipv4_address %q, expected %q", prefix, got, server_interface.IPv4Address)
+					}
+					if got := d.Get(prefix + "mac_address").(string); got != server_interface.MacAddress {
+						t.Errorf("Got %smac_address %q, expected %q", prefix, got, server_interface.MacAddress)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestSetServerAttributes_interfacesRemoved(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	server := &brightbox.Server{
+		Id:   "srv-12345",
+		Fqdn: "srv-12345.gb1.brightbox.com",
+		Interfaces: []brightbox.ServerInterface{
+			{Id: "int-aaaaa", MacAddress: "02:24:19:00:00:01", IPv4Address: "10.0.0.1", IPv6Address: "2a02:1348::1"},
+		},
+	}
+	if err := setServerAttributes(d, server); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	server.Interfaces = nil
+	if err := setServerAttributes(d, server); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, key := range []string{"interface", "mac_address", "ipv4_address_private", "ipv6_address", "ipv6_hostname"} {
+		if got := d.Get(key).(string); got != "" {
+			t.Errorf("Expected %s to be cleared once the server has no interfaces, got %q", key, got)
+		}
+	}
+}
+
+func TestFindImageByName(t *testing.T) {
+	older := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	images := []brightbox.Image{
+		{Id: "img-aaaaa", Name: "ubuntu-bionic-18.04-amd64-server", Status: "available", Official: true, CreatedAt: older},
+		{Id: "img-bbbbb", Name: "ubuntu-bionic-18.04-amd64-server", Status: "available", Official: true, CreatedAt: newer},
+		{Id: "img-ccccc", Name: "ubuntu-bionic-custom", Status: "available", CreatedAt: newer},
+		{Id: "img-ddddd", Name: "ubuntu-xenial-16.04-amd64-server", Status: "deleted", Official: true, CreatedAt: newer},
+		{Id: "img-eeeee", Name: "my-xenial", Status: "available", CreatedAt: older},
+	}
+	var nameTests = []struct {
+		name     string
+		expected string
+	}{
+		{"ubuntu-bionic", "img-bbbbb"},
+		{"^ubuntu-bionic-custom$", "img-ccccc"},
+		{"xenial", "img-eeeee"},
+		{"centos", ""},
+		{"[", ""},
+	}
+	for _, example := range nameTests {
+		image, err := findImageByName(images, example.name)
+		if example.expected == "" {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %s", example.name, image.Id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", example.name, err)
+		} else if image.Id != example.expected {
+			t.Errorf("%q: got %s, expected %s", example.name, image.Id, example.expected)
+		}
+	}
+}
+
+func TestSetServerAttributes_imageName(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	d.Set("image", "ubuntu-bionic")
+	server := &brightbox.Server{
+		Id:    "srv-12345",
+		Image: brightbox.Image{Id: "img-bbbbb"},
+	}
+	if err := setServerAttributes(d, server); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("image").(string); got != "ubuntu-bionic" {
+		t.Errorf("Got image %q, expected the configured name", got)
+	}
+	if got := d.Get("image_id").(string); got != "img-bbbbb" {
+		t.Errorf("Got image_id %q, expected img-bbbbb", got)
+	}
+}
+
+func TestSetServerAttributes_unknownServerType(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	d.Set("type", "1gb.ssd")
+	d.Set("ram", 1024)
+	d.Set("cores", 1)
+	server := &brightbox.Server{
+		Id:         "srv-12345",
+		ServerType: brightbox.ServerType{Id: "typ-zzzzz"},
+	}
+	if err := setServerAttributes(d, server); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got := d.Get("type").(string); got != "1gb.ssd" {
+		t.Errorf("Got type %q, expected the last known type", got)
+	}
+	if got := d.Get("ram").(int); got != 1024 {
+		t.Errorf("Got ram %d, expected the last known ram", got)
+	}
+	if got := d.Get("cores").(int); got != 1 {
+		t.Errorf("Got cores %d, expected the last known cores", got)
+	}
+}
+
+func TestSetServerAttributes_consoleAvailable(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	server := &brightbox.Server{Id: "srv-12345", Status: "active"}
+	setServerAttributes(d, server)
+	if !d.Get("console_available").(bool) {
+		t.Errorf("Expected an active server to offer a console")
+	}
+	server.Status = "inactive"
+	setServerAttributes(d, server)
+	if d.Get("console_available").(bool) {
+		t.Errorf("Expected an inactive server not to offer a console")
+	}
+}
+
+func TestServerEgressIp(t *testing.T) {
+	server_interface := brightbox.ServerInterface{
+		Id:          "int-12345",
+		IPv4Address: "10.240.1.2",
+		IPv6Address: "2a02:1348:14c:1::1",
+	}
+	var egressTests = []struct {
+		name     string
+		server   brightbox.Server
+		expected string
+	}{
+		{"no interfaces", brightbox.Server{}, ""},
+		{"no cloud ip", brightbox.Server{
+			Interfaces: []brightbox.ServerInterface{server_interface},
+		}, ""},
+		{"cloud ip", brightbox.Server{
+			Interfaces: []brightbox.ServerInterface{server_interface},
+			CloudIPs: []brightbox.CloudIP{
+				{Id: "cip-aaaaa", PublicIP: "109.107.35.1", PublicIPv4: "109.107.35.1"},
+				{Id: "cip-bbbbb", PublicIP: "109.107.35.2", PublicIPv4: "109.107.35.2"},
+			},
+		}, "109.107.35.1"},
+	}
+	for _, example := range egressTests {
+		if got := serverEgressIp(&example.server); got != example.expected {
+			t.Errorf("%s: got %q, expected %q", example.name, got, example.expected)
+		}
+	}
+}
+
+func TestSetServerAttributes_drift(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	d.Set("user_data", "#cloud-config\n")
+	server := &brightbox.Server{
+		Id:       "srv-12345",
+		Status:   "active",
+		UserData: base64.StdEncoding.EncodeToString([]byte("#cloud-config\n")),
+		CloudIPs: []brightbox.CloudIP{
+			{Id: "cip-12345", PublicIP: "109.107.35.1", PublicIPv6: "2a02:1348:ffff:ffff::6d6b:2301",
+				Fqdn: "cip-12345.gb1.brightbox.com"},
+		},
+	}
+	setServerAttributes(d, server)
+	if got := d.Get("ipv4_address").(string); got != "109.107.35.1" {
+		t.Errorf("Got ipv4_address %q", got)
+	}
+	if got := d.Get("public_ipv4").(string); got != "109.107.35.1" {
+		t.Errorf("Got public_ipv4 %q", got)
+	}
+	if got := d.Get("public_ipv6").(string); got != "2a02:1348:ffff:ffff::6d6b:2301" {
+		t.Errorf("Got public_ipv6 %q", got)
+	}
+	if got := d.Get("user_data").(string); got != userDataHashSum(server.UserData) {
+		t.Errorf("Got user_data %q, expected the hash of the user data", got)
+	}
+
+	// Unmapping the Cloud IP and removing the user data out of band
+	// must show up in the next plan.
+	server.CloudIPs = nil
+	server.UserData = ""
+	setServerAttributes(d, server)
+	for _, key := range []string{"ipv4_address", "public_ipv4", "public_ipv6", "public_hostname", "user_data"} {
+		if got := d.Get(key).(string); got != "" {
+			t.Errorf("Expected %s to be cleared, got %q", key, got)
+		}
+	}
+}
+
+func TestSetServerAttributes_timestamps(t *testing.T) {
+	created := time.Date(2019, 7, 1, 9, 0, 0, 0, time.UTC)
+	started := time.Date(2019, 7, 2, 18, 30, 0, 0, time.UTC)
+	d := resourceBrightboxServer().Data(nil)
+	server := &brightbox.Server{Id: "srv-12345", Status: "active", CreatedAt: &created, StartedAt: &started}
+	setServerAttributes(d, server)
+	if got := d.Get("created_at").(string); got != "2019-07-01T09:00:00Z" {
+		t.Errorf("Got created_at %q", got)
+	}
+	if got := d.Get("started_at").(string); got != "2019-07-02T18:30:00Z" {
+		t.Errorf("Got started_at %q", got)
+	}
+	server.StartedAt = nil
+	setServerAttributes(d, server)
+	if got := d.Get("started_at").(string); got != "" {
+		t.Errorf("Expected a server that has never started to have no started_at, got %q", got)
+	}
+}
+
+func TestImageOSFamily(t *testing.T) {
+	var familyTests = []struct {
+		image    brightbox.Image
+		expected string
+	}{
+		{brightbox.Image{Name: "ubuntu-bionic-18.04-amd64-server"}, "linux"},
+		{brightbox.Image{Name: "Windows 2012 Server"}, "windows"},
+		{brightbox.Image{Name: "win2k12", LicenceName: "windows-2012-server"}, "windows"},
+	}
+	for _, example := range familyTests {
+		if got := imageOSFamily(&example.image); got != example.expected {
+			t.Errorf("%q: got %q, expected %q", example.image.Name, got, example.expected)
+		}
+	}
+}
+
+func TestSetConnectionDetails(t *testing.T) {
+	var connectionTests = []struct {
+		name       string
+		family     string
+		noUsername bool
+		settings   []interface{}
+		expected   map[string]string
+	}{
+		{
+			name:     "linux",
+			family:   "linux",
+			expected: map[string]string{"type": "ssh", "host": "srv-12345.gb1.brightbox.com", "user": "ubuntu"},
+		},
+		{
+			name:   "windows",
+			family: "windows",
+			expected: map[string]string{"type": "winrm", "host": "srv-12345.gb1.brightbox.com", "user": "ubuntu",
+				"port": "5985", "https": "false"},
+		},
+		{
+			name:   "windows over https",
+			family: "windows",
+			settings: []interface{}{
+				map[string]interface{}{"type": "", "user": "Administrator", "port": 0, "https": true},
+			},
+			expected: map[string]string{"type": "winrm", "host": "srv-12345.gb1.brightbox.com", "user": "Administrator",
+				"port": "5986", "https": "true"},
+		},
+		{
+			name:       "no image username",
+			family:     "windows",
+			noUsername: true,
+			expected: map[string]string{"type": "winrm", "host": "srv-12345.gb1.brightbox.com", "user": "Administrator",
+				"port": "5985", "https": "false"},
+		},
+		{
+			name:   "ssh on another port",
+			family: "windows",
+			settings: []interface{}{
+				map[string]interface{}{"type": "ssh", "user": "", "port": 2222, "https": false},
+			},
+			expected: map[string]string{"type": "ssh", "host": "srv-12345.gb1.brightbox.com", "user": "ubuntu",
+				"port": "2222"},
+		},
+	}
+	for _, example := range connectionTests {
+		d := resourceBrightboxServer().Data(nil)
+		d.SetId("srv-12345")
+		d.Set("fqdn", "srv-12345.gb1.brightbox.com")
+		if !example.noUsername {
+			d.Set("username", "ubuntu")
+		}
+		d.Set("os_family", example.family)
+		d.Set("connection_settings", example.settings)
+		setConnectionDetails(d)
+		if got := d.State().Ephemeral.ConnInfo; !reflect.DeepEqual(got, example.expected) {
+			t.Errorf("%s: got %v, expected %v", example.name, got, example.expected)
+		}
+		if got := d.Get("connection_user").(string); got != example.expected["user"] {
+			t.Errorf("%s: got connection_user %q, expected %q", example.name, got, example.expected["user"])
+		}
+	}
+}
+
+func TestServerCustomizeDiff_serverGroups(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "srv-12345",
+		Attributes: map[string]string{
+			"id":              "srv-12345",
+			"image":           "img-12345",
+			"server_groups.#": "1",
+			fmt.Sprintf("server_groups.%d", schema.HashString("grp-aaaaa")): "grp-aaaaa",
+		},
+	}
+	_, err := resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"image":         "img-12345",
+		"server_groups": []interface{}{},
+	}), &CompositeClient{})
+	if err == nil || !strings.Contains(err.Error(), "at least one server group") {
+		t.Errorf("Expected an error emptying server_groups, got %v", err)
+	}
+
+	diff, err := resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		"image":         "img-12345",
+		"server_groups": []interface{}{"grp-bbbbb"},
+	}), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("Unexpected error swapping groups: %s", err)
+	}
+	d, err := schema.InternalMap(resourceBrightboxServer().Schema).Data(state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	opts := &brightbox.ServerOptions{Id: "srv-12345"}
+	if err := addUpdateableServerOptions(d, opts, &CompositeClient{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(opts.ServerGroups, []string{"grp-bbbbb"}) {
+		t.Errorf("Expected the new group list in a single update, got %v", opts.ServerGroups)
+	}
+}
+
+func TestServerCustomizeDiff_allowedServerGroups(t *testing.T) {
+	meta := &CompositeClient{AllowedServerGroups: []string{"grp-aaaaa", "grp-bbbbb"}}
+	config := func(groups ...interface{}) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"image":         "img-12345",
+			"server_groups": groups,
+		})
+	}
+	if _, err := resourceBrightboxServer().Diff(nil, config("grp-bbbbb", "grp-aaaaa"), meta); err != nil {
+		t.Errorf("Unexpected error with allowed groups: %s", err)
+	}
+	_, err := resourceBrightboxServer().Diff(nil, config("grp-aaaaa", "grp-ddddd", "grp-ccccc"), meta)
+	if err == nil || !strings.Contains(err.Error(), "server_groups grp-ccccc, grp-ddddd are not") {
+		t.Errorf("Expected an error naming the disallowed groups, got %v", err)
+	}
+	if _, err := resourceBrightboxServer().Diff(nil, config("grp-ccccc"), &CompositeClient{}); err != nil {
+		t.Errorf("Expected any group to be allowed without a policy, got %s", err)
+	}
+}
+
+func TestAddUpdateableServerOptions_rename(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "srv-12345",
+		Attributes: map[string]string{
+			"id":              "srv-12345",
+			"image":           "img-12345",
+			"name":            "web-1",
+			"server_groups.#": "1",
+			fmt.Sprintf("server_groups.%d", schema.HashString("grp-aaaaa")): "grp-aaaaa",
+		},
+	}
+	for _, name := range []string{"prod-web-1", ""} {
+		diff, err := resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(map[string]interface{}{
+			"image":         "img-12345",
+			"name":          name,
+			"server_groups": []interface{}{"grp-aaaaa"},
+		}), &CompositeClient{})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if diff.RequiresNew() {
+			t.Errorf("Expected renaming to %q to update the server in place", name)
+		}
+		d, err := schema.InternalMap(resourceBrightboxServer().Schema).Data(state, diff)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		opts := &brightbox.ServerOptions{Id: "srv-12345"}
+		if err := addUpdateableServerOptions(d, opts, &CompositeClient{}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if opts.Name == nil || *opts.Name != name {
+			t.Errorf("Expected the name %q to be sent, got %v", name, opts.Name)
+		}
+		if opts.ServerGroups != nil {
+			t.Errorf("Expected a rename to leave the server groups alone, got %v", opts.ServerGroups)
+		}
+	}
+}
+
+func TestAddUpdateableServerOptions_resize(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "srv-12345",
+		Attributes: map[string]string{
+			"id":              "srv-12345",
+			"image":           "img-12345",
+			"name":            "web-1",
+			"type":            "1gb.ssd",
+			"server_groups.#": "1",
+			fmt.Sprintf("server_groups.%d", schema.HashString("grp-aaaaa")): "grp-aaaaa",
+		},
+	}
+	var resizeTests = []struct {
+		name     string
+		config   map[string]interface{}
+		expected string
+	}{
+		{"resize", map[string]interface{}{"name": "web-1", "type": "2gb.ssd"}, "2gb.ssd"},
+		{"rename", map[string]interface{}{"name": "web-2", "type": "1gb.ssd"}, ""},
+	}
+	for _, example := range resizeTests {
+		example.config["image"] = "img-12345"
+		example.config["server_groups"] = []interface{}{"grp-aaaaa"}
+		diff, err := resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(example.config), &CompositeClient{})
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if diff.RequiresNew() {
+			t.Errorf("%s: expected the server to be updated in place", example.name)
+		}
+		d, err := schema.InternalMap(resourceBrightboxServer().Schema).Data(state, diff)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		opts := &brightbox.ServerOptions{Id: "srv-12345"}
+		if err := addUpdateableServerOptions(d, opts, &CompositeClient{}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if opts.ServerType != example.expected {
+			t.Errorf("%s: got server type %q, expected %q", example.name, opts.ServerType, example.expected)
+		}
+	}
+}
+
+func TestServerLock(t *testing.T) {
+	locked := false
+	destroyed := false
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/1.0/servers/srv-12345/lock_resource":
+			locked = true
+		case r.Method == "PUT" && r.URL.Path == "/1.0/servers/srv-12345/unlock_resource":
+			locked = false
+		case r.Method == "DELETE" && r.URL.Path == "/1.0/servers/srv-12345":
+			destroyed = true
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == "GET" && r.URL.Path == "/1.0/servers/srv-12345":
+			fmt.Fprintf(w, `{"id":"srv-12345","status":"active","locked":%t}`, locked)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	if err := setServerLock(client, "srv-12345", true); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !locked {
+		t.Fatalf("Expected the server to be locked")
+	}
+	d := resourceBrightboxServer().Data(nil)
+	d.SetId("srv-12345")
+	err := resourceBrightboxServerDelete(d, meta)
+	if err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("Expected deleting a locked server to fail, got %v", err)
+	}
+	if destroyed {
+		t.Errorf("Expected a locked server not to be destroyed")
+	}
+
+	if err := setServerLock(client, "srv-12345", false); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if locked {
+		t.Errorf("Expected the server to be unlocked")
+	}
+}
+
+func TestServerLockedDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "srv-12345",
+		Attributes: map[string]string{
+			"id":              "srv-12345",
+			"image":           "img-12345",
+			"locked":          "true",
+			"server_groups.#": "1",
+			fmt.Sprintf("server_groups.%d", schema.HashString("grp-aaaaa")): "grp-aaaaa",
+		},
+	}
+	config := map[string]interface{}{
+		"image":         "img-12345",
+		"server_groups": []interface{}{"grp-aaaaa"},
+	}
+	diff, err := resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil && diff.Attributes["locked"] != nil {
+		t.Errorf("Expected a lock set outside Terraform to be kept, got %#v", diff.Attributes["locked"])
+	}
+
+	config["locked"] = false
+	diff, err = resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Attributes["locked"] == nil || diff.Attributes["locked"].New != "false" {
+		t.Errorf("Expected locked = false to unlock the server")
+	}
+}
+
+func TestAssignGeneratedName(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	var name *string
+	assignGeneratedName(d, &name, "")
+	if name != nil {
+		t.Errorf("Expected no name without a prefix, got %q", *name)
+	}
+
+	assignGeneratedName(d, &name, "tf-web")
+	if name == nil || !regexp.MustCompile("^tf-web-[0-9a-f]{8}$").MatchString(*name) {
+		t.Fatalf("Expected a generated name, got %v", name)
+	}
+	if got := d.Get("generated_name").(string); got != *name {
+		t.Errorf("Got generated_name %q, expected %q", got, *name)
+	}
+
+	given := "web"
+	name = &given
+	assignGeneratedName(d, &name, "tf-web")
+	if *name != "web" {
+		t.Errorf("Expected the resource name to win, got %q", *name)
+	}
+}
+
+func TestSuppressGeneratedName(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "srv-12345",
+		Attributes: map[string]string{
+			"id":              "srv-12345",
+			"image":           "img-12345",
+			"name":            "tf-web-3f9a0c2e",
+			"generated_name":  "tf-web-3f9a0c2e",
+			"server_groups.#": "1",
+			fmt.Sprintf("server_groups.%d", schema.HashString("grp-aaaaa")): "grp-aaaaa",
+		},
+	}
+	config := func(name string) *terraform.ResourceConfig {
+		return terraform.NewResourceConfigRaw(map[string]interface{}{
+			"image":         "img-12345",
+			"name":          name,
+			"server_groups": []interface{}{"grp-aaaaa"},
+		})
+	}
+	diff, err := resourceBrightboxServer().Diff(state, config(""), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff != nil && diff.Attributes["name"] != nil {
+		t.Errorf("Expected the generated name to be kept, got %#v", diff.Attributes["name"])
+	}
+	diff, err = resourceBrightboxServer().Diff(state, config("web"), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff == nil || diff.Attributes["name"] == nil || diff.Attributes["name"].New != "web" {
+		t.Errorf("Expected a configured name to replace the generated one")
+	}
+}
+
+func TestCreateServerWithFallback(t *testing.T) {
+	var zones []string
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var opts brightbox.ServerOptions
+		json.NewDecoder(r.Body).Decode(&opts)
+		zones = append(zones, opts.Zone)
+		w.Header().Set("Content-Type", "application/json")
+		switch opts.Zone {
+		case "gb1-a":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error_name":"insufficient_capacity","errors":["No capacity for 8gb.ssd in gb1-a"]}`))
+		case "gb1-c":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error_name":"invalid_resource","errors":["Zone is invalid"]}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprintf(w, `{"id":"srv-12345","zone":{"handle":%q}}`, opts.Zone)
+		}
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	created, err := createServerWithFallback(client, &brightbox.ServerOptions{Zone: "gb1-a"}, []string{"gb1-b"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if created.Zone.Handle != "gb1-b" || !reflect.DeepEqual(zones, []string{"gb1-a", "gb1-b"}) {
+		t.Errorf("Expected the server in the fallback zone, got %q after trying %v", created.Zone.Handle, zones)
+	}
+
+	zones = nil
+	if _, err := createServerWithFallback(client, &brightbox.ServerOptions{Zone: "gb1-c"}, []string{"gb1-b"}); err == nil {
+		t.Errorf("Expected an error that isn't about capacity to fail at once")
+	}
+	if !reflect.DeepEqual(zones, []string{"gb1-c"}) {
+		t.Errorf("Expected no fallback after an error that isn't about capacity, tried %v", zones)
+	}
+
+	if _, err := createServerWithFallback(client, &brightbox.ServerOptions{Zone: "gb1-a"}, nil); !isCapacityError(err) {
+		t.Errorf("Expected the capacity error once the zones run out, got %v", err)
+	}
+}
+
+func TestServerStateRefresh_failed(t *testing.T) {
+	status := "creating"
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"srv-12345","status":%q}`, status)
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	if _, got, err := serverStateRefresh(client, "srv-12345")(); err != nil || got != "creating" {
+		t.Errorf("Got status %q and error %v, expected creating", got, err)
+	}
+	status = "failed"
+	_, _, err := serverStateRefresh(client, "srv-12345")()
+	if err == nil || !strings.Contains(err.Error(), "srv-12345") || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("Expected an error naming the failed server, got %v", err)
+	}
+	// Deleting a failed server has to wait through the failed state
+	if _, got, err := serverStatusRefresh(client, "srv-12345")(); err != nil || got != "failed" {
+		t.Errorf("Got status %q and error %v, expected failed", got, err)
+	}
+}
+
+func TestServerRebootRefresh(t *testing.T) {
+	status, started := "active", "2019-07-02T18:30:00Z"
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"srv-12345","status":%q,"started_at":%q}`, status, started)
+	})
+	defer server.Close()
+	client := meta.ApiClient
+	before := time.Date(2019, 7, 2, 18, 30, 0, 0, time.UTC)
+	refresh := serverRebootRefresh(client, "srv-12345", &before)
+
+	if _, got, err := refresh(); err != nil || got != "rebooting" {
+		t.Errorf("Got %q and error %v before the server restarted, expected rebooting", got, err)
+	}
+	status = "inactive"
+	if _, got, err := refresh(); err != nil || got != "inactive" {
+		t.Errorf("Got %q and error %v while the server was down, expected inactive", got, err)
+	}
+	status, started = "active", "2019-07-02T18:31:00Z"
+	if _, got, err := refresh(); err != nil || got != "active" {
+		t.Errorf("Got %q and error %v once the server restarted, expected active", got, err)
+	}
+}
+
+func TestServerPortRefresh(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	address := listener.Addr().String()
+	if _, got, err := serverPortRefresh(address)(); err != nil || got != "ready" {
+		t.Errorf("Got %q and error %v for a listening port, expected ready", got, err)
+	}
+	listener.Close()
+	if _, got, err := serverPortRefresh(address)(); err != nil || got != "waiting" {
+		t.Errorf("Got %q and error %v for a closed port, expected waiting", got, err)
+	}
+}
+
+func TestWaitForCloudInit_deadlinePassed(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	d.SetId("srv-12345")
+	d.Set("cloud_init_port", 8080)
+	d.SetConnInfo(map[string]string{"type": "ssh", "host": "127.0.0.1"})
+	err := waitForCloudInit(d, time.Now().Add(-time.Second))
+	if err == nil || !strings.Contains(err.Error(), "127.0.0.1:8080") {
+		t.Errorf("Expected an error once the create deadline has passed, got %v", err)
+	}
+}
+
+func TestUpdateServerGroupMembership(t *testing.T) {
+	var requests []string
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"grp-12345"}`))
+	})
+	defer server.Close()
+	client := meta.ApiClient
+
+	groups := func(ids ...interface{}) *schema.Set {
+		return schema.NewSet(schema.HashString, ids)
+	}
+	var membershipTests = []struct {
+		name     string
+		old      *schema.Set
+		new      *schema.Set
+		expected []string
+	}{
+		{"add", groups("grp-aaaaa"), groups("grp-aaaaa", "grp-bbbbb"), []string{
+			"POST /1.0/server_groups/grp-bbbbb/add_servers",
+		}},
+		{"remove", groups("grp-aaaaa", "grp-bbbbb"), groups("grp-aaaaa"), []string{
+			"POST /1.0/server_groups/grp-bbbbb/remove_servers",
+		}},
+		{"swap", groups("grp-aaaaa"), groups("grp-bbbbb"), []string{
+			"POST /1.0/server_groups/grp-bbbbb/add_servers",
+			"POST /1.0/server_groups/grp-aaaaa/remove_servers",
+		}},
+		{"no-op", groups("grp-aaaaa", "grp-bbbbb"), groups("grp-bbbbb", "grp-aaaaa"), nil},
+	}
+	for _, example := range membershipTests {
+		requests = nil
+		if err := updateServerGroupMembership(client, "srv-12345", example.old, example.new); err != nil {
+			t.Fatalf("%s: err: %s", example.name, err)
+		}
+		if !reflect.DeepEqual(requests, example.expected) {
+			t.Errorf("%s: got requests %v, expected %v", example.name, requests, example.expected)
+		}
+	}
+}
+
+func TestSetServerAttributes_serverGroupOrder(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	server := &brightbox.Server{
+		Id:           "srv-12345",
+		ServerGroups: []brightbox.ServerGroup{{Id: "grp-aaaaa"}, {Id: "grp-bbbbb"}},
+	}
+	setServerAttributes(d, server)
+	first := d.Get("server_groups").(*schema.Set)
+	server.ServerGroups = []brightbox.ServerGroup{{Id: "grp-bbbbb"}, {Id: "grp-aaaaa"}}
+	setServerAttributes(d, server)
+	if second := d.Get("server_groups").(*schema.Set); !first.Equal(second) {
+		t.Errorf("Expected the API's group order not to matter, got %v and %v", first.List(), second.List())
+	}
+
+	// A group joined out of band shows up on the next refresh
+	server.ServerGroups = append(server.ServerGroups, brightbox.ServerGroup{Id: "grp-ccccc"})
+	setServerAttributes(d, server)
+	if got := d.Get("server_groups").(*schema.Set); !got.Contains("grp-ccccc") || got.Len() != 3 {
+		t.Errorf("Expected the new group in server_groups, got %v", got.List())
+	}
+}
+
+func TestServerZoneFallbackDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "srv-12345",
+		Attributes: map[string]string{
+			"id":              "srv-12345",
+			"image":           "img-12345",
+			"zone":            "gb1-b",
+			"zone_fallback.#": "1",
+			"zone_fallback.0": "gb1-b",
+			"zone_requested":  "gb1-a",
+			"server_groups.#": "1",
+			fmt.Sprintf("server_groups.%d", schema.HashString("grp-aaaaa")): "grp-aaaaa",
+		},
+	}
+	config := map[string]interface{}{
+		"image":         "img-12345",
+		"zone":          "gb1-a",
+		"zone_fallback": []interface{}{"gb1-b"},
+		"server_groups": []interface{}{"grp-aaaaa"},
+	}
+	diff, err := resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Errorf("Expected a server in a fallback zone to be kept")
+	}
+
+	config["zone"] = "gb1-c"
+	diff, err = resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !diff.RequiresNew() {
+		t.Errorf("Expected a change to another zone to replace the server")
+	}
+
+	config["zone"] = "gb1-a"
+	state.Attributes["zone_requested"] = ""
+	diff, err = resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !diff.RequiresNew() {
+		t.Errorf("Expected a change of zone to replace a server that was built where it was asked to be")
+	}
+}
+
+func TestSetZoneRequested(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	setZoneRequested(d, "gb1-a", "gb1-b")
+	if got := d.Get("zone_requested").(string); got != "gb1-a" {
+		t.Errorf("Got zone_requested %q after a fallback, expected gb1-a", got)
+	}
+	setZoneRequested(d, "gb1-a", "gb1-a")
+	if got := d.Get("zone_requested").(string); got != "" {
+		t.Errorf("Got zone_requested %q without a fallback, expected it empty", got)
+	}
+}
+
+func TestServerSnapshotOnRecreateDiff(t *testing.T) {
+	state := &terraform.InstanceState{
+		ID: "srv-12345",
+		Attributes: map[string]string{
+			"id":              "srv-12345",
+			"image":           "img-12345",
+			"zone":            "gb1-a",
+			"server_groups.#": "1",
+			fmt.Sprintf("server_groups.%d", schema.HashString("grp-aaaaa")): "grp-aaaaa",
+		},
+	}
+	config := map[string]interface{}{
+		"image":         "img-12345",
+		"zone":          "gb1-b",
+		"server_groups": []interface{}{"grp-aaaaa"},
+	}
+	diff, err := resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !diff.RequiresNew() {
+		t.Errorf("Expected a zone change to replace the server")
+	}
+
+	config["snapshot_on_recreate"] = true
+	diff, err = resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if diff.RequiresNew() {
+		t.Errorf("Expected snapshot_on_recreate to rebuild the server in an update")
+	}
+	if attr := diff.Attributes["recreated_from"]; attr == nil || attr.New != "srv-12345" {
+		t.Errorf("Expected recreated_from to be srv-12345, got %v", attr)
+	}
+
+	config["image"] = "img-67890"
+	diff, err = resourceBrightboxServer().Diff(state, terraform.NewResourceConfigRaw(config), &CompositeClient{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !diff.RequiresNew() {
+		t.Errorf("Expected an image change to replace the server")
+	}
+	if attr := diff.Attributes["recreated_from"]; attr != nil && attr.New == "srv-12345" {
+		t.Errorf("Expected no rebuild when the image changes")
+	}
+}
+
+func TestServerCreateStepError(t *testing.T) {
+	err := serverCreateStepError("srv-testy", "adding it to the load balancer", fmt.Errorf("missing_resource: lba-testy"))
+	expected := "Server srv-testy was created but adding it to the load balancer failed: missing_resource: lba-testy. " +
+		"To keep the server run 'terraform untaint' on it, then apply again to complete the remaining steps"
+	if err.Error() != expected {
+		t.Errorf("Got %q, expected %q", err.Error(), expected)
+	}
+}
+
+func testAccCheckBrightboxServerRebooted(
+	before, after *brightbox.Server) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if before.StartedAt == nil || after.StartedAt == nil {
+			return fmt.Errorf("Expected server %s to have a started_at time", after.Id)
+		}
+		if !after.StartedAt.After(*before.StartedAt) {
+			return fmt.Errorf("Expected server %s to have restarted since %s, started_at is %s",
+				after.Id, before.StartedAt, after.StartedAt)
+		}
+		return nil
+	}
+}
+
 func testAccCheckBrightboxServerRecreated(t *testing.T,
 	before, after *brightbox.Server) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
@@ -400,6 +1650,124 @@ data "brightbox_server_group" "barfoo" {
 		TestAccBrightboxDataServerGroupConfig_default)
 }
 
+func testAccCheckBrightboxServerConfig_reboot_triggers(rInt int, revision string) string {
+	return fmt.Sprintf(`
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "foo-%d"
+	type = "1gb.ssd"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+	reboot_triggers = {
+		revision = "%s"
+	}
+}
+
+%s%s`, rInt, revision, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
+
+func testAccCheckBrightboxServerConfig_ram_and_cores(rInt int) string {
+	return fmt.Sprintf(`
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "foo-%d"
+	ram = 1024
+	cores = 1
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+}
+
+%s%s`, rInt, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
+
+func testAccCheckBrightboxServerConfig_load_balancer(rInt int) string {
+	return fmt.Sprintf(`
+resource "brightbox_load_balancer" "foobar" {
+	name = "foo-%d"
+	listener {
+		protocol = "http"
+		in = 80
+		out = 8080
+	}
+	healthcheck {
+		type = "http"
+		port = 8080
+	}
+}
+
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "foo-%d"
+	type = "1gb.ssd"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+	load_balancer = "${brightbox_load_balancer.foobar.id}"
+}
+
+%s%s`, rInt, rInt, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
+
+func testAccCheckBrightboxServerConfig_user_data_parts(rInt int) string {
+	return fmt.Sprintf(`
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "foo-%d"
+	type = "1gb.ssd"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+	user_data_parts {
+		content = "#cloud-config\npackages:\n - nginx\n"
+		filename = "packages.cfg"
+	}
+	user_data_parts {
+		content = "#!/bin/sh\necho hello\n"
+		content_type = "text/x-shellscript"
+	}
+}
+
+%s%s`, rInt, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
+
+func testAccCheckBrightboxServerConfig_snapshot_on_recreate(rInt int, zone string) string {
+	return fmt.Sprintf(`
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "foo-%d"
+	type = "1gb.ssd"
+	zone = "%s"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+	snapshot_on_recreate = true
+}
+
+%s%s`, rInt, zone, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
+
+func testAccCheckBrightboxServerConfig_resize(rInt int, server_type string) string {
+	return fmt.Sprintf(`
+resource "brightbox_server" "foobar" {
+	image = "${data.brightbox_image.foobar.id}"
+	name = "foo-%d"
+	type = "%s"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+}
+
+%s%s`, rInt, server_type, TestAccBrightboxImageDataSourceConfig_blank_disk,
+		TestAccBrightboxDataServerGroupConfig_default)
+}
+
+func testAccCheckBrightboxServerConfig_image_name(rInt int) string {
+	return fmt.Sprintf(`
+resource "brightbox_server" "foobar" {
+	image = "^Blank Disk Image$"
+	name = "foo-%d"
+	type = "1gb.ssd"
+	server_groups = ["${data.brightbox_server_group.default.id}"]
+}
+
+%s`, rInt, TestAccBrightboxDataServerGroupConfig_default)
+}
+
 func testAccCheckBrightboxServerConfig_rename(rInt int) string {
 	return fmt.Sprintf(`
 resource "brightbox_server" "foobar" {
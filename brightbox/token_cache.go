@@ -0,0 +1,103 @@
+package brightbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// A token saved between runs, along with the credentials it was issued
+// for so that a change of user or client is not served a stale token.
+type cachedToken struct {
+	Key   string        `json:"key"`
+	Token *oauth2.Token `json:"token"`
+}
+
+func (authd *authdetails) tokenCacheKey() string {
+	sum := sha256.Sum256([]byte(authd.tokenURL() + "\n" + authd.APIClient + "\n" + authd.UserName))
+	return hex.EncodeToString(sum[:])
+}
+
+// Read the token cached for key, if there is one that has not expired
+func readCachedToken(path string, key string) *oauth2.Token {
+	if path == "" {
+		return nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[WARN] Unable to read token cache %s: %s", path, err)
+		}
+		return nil
+	}
+	var cached cachedToken
+	if err := json.Unmarshal(content, &cached); err != nil {
+		log.Printf("[WARN] Ignoring unreadable token cache %s: %s", path, err)
+		return nil
+	}
+	if cached.Key != key || cached.Token == nil || !cached.Token.Valid() {
+		log.Printf("[DEBUG] Cached token in %s is for other credentials or has expired", path)
+		return nil
+	}
+	log.Printf("[INFO] Reusing cached token from %s, expiring %s", path, cached.Token.Expiry)
+	return cached.Token
+}
+
+// Replace the cache file in one step, readable only by its owner
+func writeCachedToken(path string, key string, token *oauth2.Token) error {
+	content, err := json.Marshal(cachedToken{Key: key, Token: token})
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// cachingTokenSource saves each new token from the wrapped source.
+// Failing to save is logged but does not stop the token being used.
+type cachingTokenSource struct {
+	source oauth2.TokenSource
+	path   string
+	key    string
+	mu     sync.Mutex
+	saved  string
+}
+
+func (s *cachingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token.AccessToken != s.saved {
+		if err := writeCachedToken(s.path, s.key, token); err != nil {
+			log.Printf("[WARN] Unable to write token cache %s: %s", s.path, err)
+		} else {
+			s.saved = token.AccessToken
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,116 @@
+package brightbox
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestTokenCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token-cache")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token.json")
+
+	if token := readCachedToken(path, "key"); token != nil {
+		t.Errorf("Expected no token without a cache file, got %v", token)
+	}
+	token := &oauth2.Token{AccessToken: "abc", Expiry: time.Now().Add(time.Hour)}
+	if err := writeCachedToken(path, "key", token); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Expected the cache to be private, got mode %o", mode)
+	}
+	if cached := readCachedToken(path, "key"); cached == nil || cached.AccessToken != "abc" {
+		t.Errorf("Expected the cached token back, got %v", cached)
+	}
+	if cached := readCachedToken(path, "other"); cached != nil {
+		t.Errorf("Expected no token for other credentials, got %v", cached)
+	}
+
+	token.Expiry = time.Now().Add(-time.Minute)
+	writeCachedToken(path, "key", token)
+	if cached := readCachedToken(path, "key"); cached != nil {
+		t.Errorf("Expected an expired token to be ignored, got %v", cached)
+	}
+}
+
+func TestCachingTokenSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token-cache")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "token.json")
+
+	token := &oauth2.Token{AccessToken: "abc", Expiry: time.Now().Add(time.Hour)}
+	source := &cachingTokenSource{
+		source: oauth2.StaticTokenSource(token),
+		path:   path,
+		key:    "key",
+	}
+	if _, err := source.Token(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if cached := readCachedToken(path, "key"); cached == nil || cached.AccessToken != "abc" {
+		t.Errorf("Expected the new token to be cached, got %v", cached)
+	}
+
+	source.path = filepath.Join(dir, "missing", "token.json")
+	if _, err := source.Token(); err != nil {
+		t.Errorf("Expected the token even when it can't be cached, got %s", err)
+	}
+}
+
+func TestGetUserTokenSource_cached(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"fresh","token_type":"Bearer","expires_in":7200}`)
+	}))
+	defer server.Close()
+	authd := &authdetails{
+		APIClient: "app-12345",
+		APISecret: "secret",
+		UserName:  "user@example.com",
+		password:  "password",
+		APIURL:    server.URL,
+	}
+
+	cached := &oauth2.Token{AccessToken: "cached", Expiry: time.Now().Add(time.Hour)}
+	if err := authd.getUserTokenSource(context.Background(), cached); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	token, err := authd.currentToken.Token()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if token.AccessToken != "cached" || requests != 0 {
+		t.Errorf("Expected the cached token without authenticating, got %q after %d requests", token.AccessToken, requests)
+	}
+
+	if err := authd.getUserTokenSource(context.Background(), nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	token, _ = authd.currentToken.Token()
+	if token.AccessToken != "fresh" || requests != 1 {
+		t.Errorf("Expected to authenticate without a cached token, got %q after %d requests", token.AccessToken, requests)
+	}
+}
@@ -0,0 +1,194 @@
+package brightbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// limitedTransport caps the number of requests in flight through the
+// wrapped RoundTripper. A slot is held until the response body is
+// closed so that streamed responses count against the limit.
+type limitedTransport struct {
+	transport http.RoundTripper
+	slots     chan struct{}
+}
+
+func newLimitedTransport(transport http.RoundTripper, limit int) http.RoundTripper {
+	if limit <= 0 {
+		return transport
+	}
+	return &limitedTransport{
+		transport: transport,
+		slots:     make(chan struct{}, limit),
+	}
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		t.release()
+		return resp, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: t.release}
+	return resp, nil
+}
+
+func (t *limitedTransport) release() {
+	<-t.slots
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// retryTransport repeats requests that Brightbox Cloud turns away with
+// a rate limit or a server error, backing off exponentially or for as
+// long as a Retry-After header asks. A 429 or 503 means the request was
+// not acted on so any method is retried. Other server errors and
+// timeouts may come after a change has been made, so only idempotent
+// requests repeat.
+type retryTransport struct {
+	transport  http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+func newRetryTransport(transport http.RoundTripper, maxRetries int) http.RoundTripper {
+	if maxRetries <= 0 {
+		return transport
+	}
+	return &retryTransport{
+		transport:  transport,
+		maxRetries: maxRetries,
+		baseDelay:  retryBaseDelay,
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.transport.RoundTrip(req)
+		if attempt >= t.maxRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		var delay time.Duration
+		if err != nil {
+			if !retryableTimeout(req, err) {
+				return resp, err
+			}
+			delay = t.retryDelay(nil, attempt)
+			log.Printf("[WARN] %s %s timed out, retrying in %s (attempt %d of %d)",
+				req.Method, req.URL.Path, delay, attempt+1, t.maxRetries)
+		} else {
+			if !retryableResponse(req, resp) {
+				return resp, nil
+			}
+			delay = t.retryDelay(resp, attempt)
+			log.Printf("[WARN] %s %s returned %s, retrying in %s (attempt %d of %d)",
+				req.Method, req.URL.Path, resp.Status, delay, attempt+1, t.maxRetries)
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func retryableResponse(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotentRequest(req)
+	}
+	return false
+}
+
+// A request that ran out of time on its own, rather than because the
+// caller gave up
+func retryableTimeout(req *http.Request, err error) bool {
+	var net_err net.Error
+	timed_out := errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &net_err) && net_err.Timeout())
+	return timed_out &&
+		req.Context().Err() == nil &&
+		idempotentRequest(req)
+}
+
+func idempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (t *retryTransport) retryDelay(resp *http.Response, attempt int) time.Duration {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if delay > retryMaxDelay {
+				return retryMaxDelay
+			}
+			return delay
+		}
+	}
+	delay := t.baseDelay << uint(attempt)
+	if delay <= 0 || delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// Retry-After is either a number of seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		if delay := when.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
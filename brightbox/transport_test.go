@@ -0,0 +1,230 @@
+package brightbox
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimitedTransport(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: newLimitedTransport(http.DefaultTransport, 2),
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", maxInFlight)
+	}
+}
+
+func TestLimitedTransport_unlimited(t *testing.T) {
+	if newLimitedTransport(http.DefaultTransport, 0) != http.DefaultTransport {
+		t.Errorf("Expected a zero limit to leave the transport unwrapped")
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	var attempts int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newRetryTransport(http.DefaultTransport, 5)}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"name":"web"}`))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Got status %d, expected 200", resp.StatusCode)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	for i, body := range bodies {
+		if body != `{"name":"web"}` {
+			t.Errorf("Attempt %d: got body %q", i, body)
+		}
+	}
+}
+
+func TestRetryTransport_limits(t *testing.T) {
+	var attempts int32
+	status := http.StatusBadGateway
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{
+		transport:  http.DefaultTransport,
+		maxRetries: 2,
+		baseDelay:  time.Millisecond,
+	}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != status || attempts != 3 {
+		t.Errorf("Expected the last of 3 attempts to be returned, got %d after %d", resp.StatusCode, attempts)
+	}
+
+	attempts = 0
+	resp, err = client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	resp.Body.Close()
+	if attempts != 1 {
+		t.Errorf("Expected a POST failing with %d not to be retried, got %d attempts", status, attempts)
+	}
+}
+
+func TestRetryTransport_disabled(t *testing.T) {
+	if newRetryTransport(http.DefaultTransport, 0) != http.DefaultTransport {
+		t.Errorf("Expected zero retries to leave the transport unwrapped")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2019, time.July, 1, 9, 0, 0, 0, time.UTC)
+	var retryAfterTests = []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"Mon, 01 Jul 2019 09:00:10 GMT", 10 * time.Second, true},
+		{"Mon, 01 Jul 2019 08:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, example := range retryAfterTests {
+		delay, ok := parseRetryAfter(example.value, now)
+		if delay != example.expected || ok != example.ok {
+			t.Errorf("%q: got %s, %v, expected %s, %v", example.value, delay, ok, example.expected, example.ok)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	transport := &retryTransport{baseDelay: time.Second}
+	resp := &http.Response{Header: http.Header{}}
+	for attempt, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		if got := transport.retryDelay(resp, attempt); got != expected {
+			t.Errorf("Attempt %d: got delay %s, expected %s", attempt, got, expected)
+		}
+	}
+	if got := transport.retryDelay(resp, 40); got != retryMaxDelay {
+		t.Errorf("Expected the delay to be capped, got %s", got)
+	}
+	resp.Header.Set("Retry-After", "120")
+	if got := transport.retryDelay(resp, 0); got != retryMaxDelay {
+		t.Errorf("Expected Retry-After to be capped, got %s", got)
+	}
+}
+
+func TestHTTPTimeout(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	authd := &authdetails{HTTPTimeout: 50 * time.Millisecond, MaxRetries: 1}
+	client := &http.Client{Transport: authd.httpTransport()}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected a timed out GET to be retried, got %s", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" || attempts != 2 {
+		t.Errorf("Got body %q after %d attempts", body, attempts)
+	}
+
+	attempts = 0
+	authd.MaxRetries = 0
+	client = &http.Client{Transport: authd.httpTransport()}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Errorf("Expected a stuck request to time out")
+	}
+}
+
+func TestHTTPTimeout_slowBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 4; i++ {
+			w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(30 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	authd := &authdetails{HTTPTimeout: 50 * time.Millisecond}
+	client := &http.Client{Transport: authd.httpTransport()}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Expected a body streamed for longer than http_timeout to be read in full, got %s", err)
+	}
+	if string(body) != strings.Repeat("chunk", 4) {
+		t.Errorf("Got body %q", body)
+	}
+}
@@ -0,0 +1,130 @@
+package brightbox
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/objectstorage/v1/objects"
+)
+
+const (
+	userDataBoundary    = "MIMEBOUNDARY-brightbox"
+	defaultPartType     = "text/cloud-config"
+	mimeMultipartHeader = "Content-Type: multipart/mixed; boundary=\"" + userDataBoundary + "\"\r\n" +
+		"MIME-Version: 1.0\r\n\r\n"
+)
+
+// Assemble user_data_parts into a cloud-init MIME multipart document.
+// The boundary is fixed so the same parts always produce the same
+// document and hash.
+func renderUserDataParts(parts []interface{}) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString(mimeMultipartHeader)
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(userDataBoundary); err != nil {
+		return "", err
+	}
+	for i, raw := range parts {
+		part := raw.(map[string]interface{})
+		content_type := part["content_type"].(string)
+		if content_type == "" {
+			content_type = defaultPartType
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", content_type+"; charset=\"utf-8\"")
+		header.Set("Mime-Version", "1.0")
+		header.Set("Content-Transfer-Encoding", "7bit")
+		if filename := part["filename"].(string); filename != "" {
+			header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		}
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return "", fmt.Errorf("Error writing user_data part %d: %s", i, err)
+		}
+		if _, err := w.Write([]byte(part["content"].(string))); err != nil {
+			return "", fmt.Errorf("Error writing user_data part %d: %s", i, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Render the parts and encode them for the API, compressing the
+// document when it would otherwise exceed the user data size limit.
+// cloud-init detects and decompresses gzipped user data itself.
+func encodeUserDataParts(parts []interface{}, limit int) (string, error) {
+	document, err := renderUserDataParts(parts)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(document))
+	if len(encoded) <= limit {
+		return encoded, nil
+	}
+	return gzipUserData([]byte(document))
+}
+
+// Compress user data and encode it for the API.
+func gzipUserData(content []byte) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// Fetch the content of an Orbit object given as "container/object".
+func orbitObjectContent(client *gophercloud.ServiceClient, path string) ([]byte, error) {
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Invalid Orbit object path %q, expected container/object", path)
+	}
+	result := objects.Download(client, parts[0], parts[1], nil)
+	content, err := result.ExtractContent()
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching Orbit object %s: %s", path, err)
+	}
+	return content, nil
+}
+
+// Hash user data as received by the server. Gzipped user data is
+// hashed once decompressed so it matches the hash of the configuration.
+func decodedUserDataHashSum(base64_userdata string) string {
+	hash := sha1.Sum([]byte(decodeUserData(base64_userdata)))
+	return hex.EncodeToString(hash[:])
+}
+
+// Decode user data as received by the server, decompressing it if it
+// was gzipped. Data that is not valid base64 is returned unchanged.
+func decodeUserData(base64_userdata string) string {
+	decoded, err := base64.StdEncoding.DecodeString(base64_userdata)
+	if err != nil {
+		return base64_userdata
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(decoded))
+	if err != nil {
+		return string(decoded)
+	}
+	plain, err := ioutil.ReadAll(zr)
+	if err != nil {
+		log.Printf("[WARN] Unable to decompress user data: %s", err)
+		return string(decoded)
+	}
+	return string(plain)
+}
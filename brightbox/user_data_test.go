@@ -0,0 +1,255 @@
+package brightbox
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/brightbox/gobrightbox"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+var testUserDataParts = []interface{}{
+	map[string]interface{}{
+		"content":      "#cloud-config\npackages:\n - nginx\n",
+		"content_type": "text/cloud-config",
+		"filename":     "packages.cfg",
+	},
+	map[string]interface{}{
+		"content":      "#!/bin/sh\necho hello\n",
+		"content_type": "text/x-shellscript",
+		"filename":     "",
+	},
+}
+
+func TestRenderUserDataParts(t *testing.T) {
+	document, err := renderUserDataParts(testUserDataParts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	again, _ := renderUserDataParts(testUserDataParts)
+	if document != again {
+		t.Errorf("Expected rendering to be repeatable")
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("Error parsing document: %s", err)
+	}
+	media_type, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("Error parsing content type: %s", err)
+	}
+	if media_type != "multipart/mixed" {
+		t.Errorf("Got media type %q, expected multipart/mixed", media_type)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for i, raw := range testUserDataParts {
+		expected := raw.(map[string]interface{})
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("Part %d: %s", i, err)
+		}
+		if got := part.Header.Get("Content-Type"); !strings.HasPrefix(got, expected["content_type"].(string)) {
+			t.Errorf("Part %d: got content type %q", i, got)
+		}
+		if got := part.FileName(); got != expected["filename"] {
+			t.Errorf("Part %d: got filename %q, expected %q", i, got, expected["filename"])
+		}
+		content, _ := ioutil.ReadAll(part)
+		if string(content) != expected["content"] {
+			t.Errorf("Part %d: got content %q, expected %q", i, content, expected["content"])
+		}
+	}
+}
+
+func TestEncodeUserDataParts_gzip(t *testing.T) {
+	encoded, err := encodeUserDataParts(testUserDataParts, userdata_size_limit)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	decoded, _ := base64.StdEncoding.DecodeString(encoded)
+	if !bytes.HasPrefix(decoded, []byte("Content-Type: multipart/mixed")) {
+		t.Errorf("Expected a small document to be left uncompressed")
+	}
+
+	large := []interface{}{
+		map[string]interface{}{
+			"content":      "#cloud-config\n" + strings.Repeat("# padding\n", 2000),
+			"content_type": "text/cloud-config",
+			"filename":     "",
+		},
+	}
+	encoded, err = encodeUserDataParts(large, userdata_size_limit)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(encoded) > userdata_size_limit {
+		t.Errorf("Expected compressed user data within the limit, got %d bytes", len(encoded))
+	}
+	decoded, _ = base64.StdEncoding.DecodeString(encoded)
+	zr, err := gzip.NewReader(bytes.NewReader(decoded))
+	if err != nil {
+		t.Fatalf("Expected gzipped user data: %s", err)
+	}
+	document, _ := ioutil.ReadAll(zr)
+	expected, _ := renderUserDataParts(large)
+	if string(document) != expected {
+		t.Errorf("Decompressed user data does not match the rendered document")
+	}
+
+	encoded, err = encodeUserDataParts(large, 4*userdata_size_limit)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	decoded, _ = base64.StdEncoding.DecodeString(encoded)
+	if string(decoded) != expected {
+		t.Errorf("Expected a document within a raised limit to be left uncompressed")
+	}
+}
+
+func TestSetUserDataDetails_parts(t *testing.T) {
+	d := resourceBrightboxServer().Data(nil)
+	d.Set("user_data_parts", testUserDataParts)
+	encoded, _ := encodeUserDataParts(testUserDataParts, userdata_size_limit)
+
+	setUserDataDetails(d, encoded)
+	if got := len(d.Get("user_data_parts").([]interface{})); got != 2 {
+		t.Errorf("Expected matching user data to keep the parts, got %d", got)
+	}
+
+	setUserDataDetails(d, base64.StdEncoding.EncodeToString([]byte("#cloud-config\n")))
+	if got := len(d.Get("user_data_parts").([]interface{})); got != 0 {
+		t.Errorf("Expected changed user data to clear the parts, got %d", got)
+	}
+}
+
+func TestDecodeUserData(t *testing.T) {
+	document, _ := renderUserDataParts(testUserDataParts)
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(document))
+	zw.Close()
+	var decodeTests = []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"plain", base64.StdEncoding.EncodeToString([]byte("#cloud-config\n")), "#cloud-config\n"},
+		{"gzipped", base64.StdEncoding.EncodeToString(buf.Bytes()), document},
+		{"not base64", "#cloud-config", "#cloud-config"},
+	}
+	for _, example := range decodeTests {
+		if got := decodeUserData(example.input); got != example.expected {
+			t.Errorf("%s: got %q, expected %q", example.name, got, example.expected)
+		}
+	}
+}
+
+func TestDecodedUserDataHashSum(t *testing.T) {
+	plain := "#cloud-config\n"
+	compressed, err := gzipUserData([]byte(plain))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := hash_string(plain)
+	if got := decodedUserDataHashSum(compressed); got != expected {
+		t.Errorf("Got hash %q for gzipped user data, expected %q", got, expected)
+	}
+	if got := decodedUserDataHashSum(base64Encode(plain)); got != expected {
+		t.Errorf("Got hash %q for plain user data, expected %q", got, expected)
+	}
+}
+
+func TestAddUpdateableServerOptions_gzip(t *testing.T) {
+	user_data := "#cloud-config\n" + strings.Repeat("# padding\n", 2000)
+	meta := &CompositeClient{UserDataLimit: userdata_size_limit}
+	encode := func(compress bool) (*brightbox.ServerOptions, error) {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"image":          "img-12345",
+			"server_groups":  []interface{}{"grp-aaaaa"},
+			"user_data":      user_data,
+			"user_data_gzip": compress,
+		})
+		diff, err := resourceBrightboxServer().Diff(nil, config, meta)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		d, err := schema.InternalMap(resourceBrightboxServer().Schema).Data(nil, diff)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		opts := &brightbox.ServerOptions{}
+		return opts, addUpdateableServerOptions(d, opts, meta)
+	}
+
+	_, err := encode(false)
+	if err == nil || !strings.Contains(err.Error(), "user_data_gzip") {
+		t.Errorf("Expected an error suggesting user_data_gzip, got %v", err)
+	}
+
+	opts, err := encode(true)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if opts.UserData == nil {
+		t.Fatalf("Expected compressed user data to be sent")
+	}
+	if got := decodeUserData(*opts.UserData); got != user_data {
+		t.Errorf("Expected the user data to decompress to the original")
+	}
+	if got := decodedUserDataHashSum(*opts.UserData); got != hash_string(user_data) {
+		t.Errorf("Expected the compressed user data to read back with the configured hash")
+	}
+}
+
+func TestSetUserDataDetails_expose(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("#cloud-config\n"))
+	d := resourceBrightboxServer().Data(nil)
+	setUserDataDetails(d, encoded)
+	if got := d.Get("user_data_plaintext").(string); got != "" {
+		t.Errorf("Expected no plaintext by default, got %q", got)
+	}
+	d.Set("expose_user_data", true)
+	setUserDataDetails(d, encoded)
+	if got := d.Get("user_data_plaintext").(string); got != "#cloud-config\n" {
+		t.Errorf("Got plaintext %q, expected the decoded user data", got)
+	}
+	if got := d.Get("user_data").(string); got != userDataHashSum(encoded) {
+		t.Errorf("Expected user_data to keep holding the hash, got %q", got)
+	}
+}
+
+func TestOrbitObjectContent(t *testing.T) {
+	meta, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config/web/cloud-config.yml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("#cloud-config\n"))
+	})
+	defer server.Close()
+	client := meta.OrbitClient
+
+	content, err := orbitObjectContent(client, "config/web/cloud-config.yml")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(content) != "#cloud-config\n" {
+		t.Errorf("Got content %q", content)
+	}
+	if _, err := orbitObjectContent(client, "config/missing.yml"); err == nil {
+		t.Errorf("Expected an error for a missing object")
+	}
+	if _, err := orbitObjectContent(client, "config"); err == nil {
+		t.Errorf("Expected an error for a path without an object name")
+	}
+}
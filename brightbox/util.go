@@ -1,12 +1,15 @@
 package brightbox
 
 import (
+	"crypto/rand"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"log"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/brightbox/gobrightbox"
 	"github.com/gophercloud/gophercloud"
@@ -42,6 +45,33 @@ func userDataHashSum(user_data string) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// Eight random hex digits, short enough to keep generated names readable
+func shortUniqueId() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", time.Now().UnixNano()&0xffffffff)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Name a resource being created without a name from the provider's
+// name_prefix, recording the name in generated_name.
+func assignGeneratedName(d *schema.ResourceData, target **string, prefix string) {
+	if prefix == "" || (*target != nil && **target != "") {
+		return
+	}
+	name := prefix + "-" + shortUniqueId()
+	log.Printf("[INFO] No name given, using %s", name)
+	*target = &name
+	d.Set("generated_name", name)
+}
+
+// Keep a generated name while the configuration has no name, rather
+// than planning to clear it.
+func suppressGeneratedName(k, old, new string, d *schema.ResourceData) bool {
+	return new == "" && old != "" && old == d.Get("generated_name").(string)
+}
+
 func assign_string(d *schema.ResourceData, target **string, index string) {
 	if d.HasChange(index) {
 		if *target == nil {
@@ -79,6 +109,22 @@ func flatten_string_slice(list []string) []interface{} {
 	return temp
 }
 
+func map_from_string_list(source []interface{}) []string {
+	temp := make([]string, len(source))
+	for i, v := range source {
+		temp[i] = v.(string)
+	}
+	return temp
+}
+
+func map_from_string_map(source map[string]interface{}) map[string]string {
+	temp := make(map[string]string, len(source))
+	for k, v := range source {
+		temp[strings.ToLower(k)] = v.(string)
+	}
+	return temp
+}
+
 func assign_int(d *schema.ResourceData, target **int, index string) {
 	if d.HasChange(index) {
 		var temp int
@@ -145,13 +191,46 @@ func mustBeBase64Encoded(v interface{}, name string) ([]string, []error) {
 	)
 }
 
+// Plain text in user_data that happens to be valid base64 is passed
+// through unchanged rather than encoded again, so say so.
+func warnIfBase64Encoded(v interface{}, name string) (warns []string, errors []error) {
+	value := v.(string)
+	if value != "" && isBase64Encoded(value) {
+		warns = append(warns, fmt.Sprintf(
+			"%q looks base64 encoded and will be sent unchanged. Use user_data_base64 for encoded data, "+
+				"or user_data_base64 = base64encode(...) if this is plain text", name))
+	}
+	return
+}
+
+// Brightbox schedules take the five fields of a crontab entry, without
+// the seconds and year fields the parser also accepts
 func ValidateCronString(v interface{}, name string) (warns []string, errors []error) {
-	if _, err := cronexpr.Parse(v.(string)); err != nil {
+	if fields := len(strings.Fields(v.(string))); fields != 5 {
+		errors = append(errors, fmt.Errorf("%q must have five fields, got %d", name, fields))
+	} else if _, err := cronexpr.Parse(v.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q: %s", name, err))
+	}
+	return
+}
+
+func ValidateDurationString(v interface{}, name string) (warns []string, errors []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
 		errors = append(errors, fmt.Errorf("%q: %s", name, err))
 	}
 	return
 }
 
+func ValidatePositiveDurationString(v interface{}, name string) (warns []string, errors []error) {
+	duration, err := time.ParseDuration(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q: %s", name, err))
+	} else if duration <= 0 {
+		errors = append(errors, fmt.Errorf("%q must be a positive duration, got %s", name, duration))
+	}
+	return
+}
+
 func http1Keys(v interface{}, name string) (warns []string, errors []error) {
 	mapValue, ok := v.(map[string]interface{})
 	if !ok {
@@ -314,3 +393,12 @@ func CheckDeleted(d *schema.ResourceData, err error, msg string) error {
 
 	return fmt.Errorf("%s %s: %s", msg, d.Id(), err)
 }
+
+// Format an optional API timestamp, giving the empty string when it is
+// not set
+func optionalTimeString(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -1,6 +1,7 @@
 package brightbox
 
 import (
+	"encoding/base64"
 	"fmt"
 	"testing"
 
@@ -11,6 +12,9 @@ func TestValidateCron(t *testing.T) {
 	testCases := []StringValidationTestCase{
 		{"Valid Cron", "5 4 * * *", false},
 		{"Invalid Cron", "apple", true},
+		{"Out of range Cron", "5 25 * * *", true},
+		{"Cron with seconds", "0 5 4 * * *", true},
+		{"Cron with year", "5 4 * * * 2030", true},
 	}
 	es := testStringValidationCases(testCases, ValidateCronString)
 	if len(es) > 0 {
@@ -45,6 +49,40 @@ func TestValidateKeys(t *testing.T) {
 	}
 }
 
+func TestBase64Encode(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("hello world"))
+	if got := base64Encode("hello world"); got != encoded {
+		t.Errorf("Got %q, expected %q", got, encoded)
+	}
+	if got := base64Encode(encoded); got != encoded {
+		t.Errorf("Already encoded data was encoded again: %q", got)
+	}
+	if userDataHashSum("hello world") != userDataHashSum(encoded) {
+		t.Errorf("Expected the same hash for plain and encoded user data")
+	}
+}
+
+func TestWarnIfBase64Encoded(t *testing.T) {
+	var warnTests = []struct {
+		value string
+		warn  bool
+	}{
+		{"", false},
+		{"#cloud-config\npackages: [nginx]\n", false},
+		{"foo:-with-character's", false},
+		{base64.StdEncoding.EncodeToString([]byte("#!/bin/sh\n")), true},
+	}
+	for _, example := range warnTests {
+		warns, errs := warnIfBase64Encoded(example.value, "user_data")
+		if len(errs) > 0 {
+			t.Errorf("%q: unexpected errors %v", example.value, errs)
+		}
+		if got := len(warns) > 0; got != example.warn {
+			t.Errorf("%q: got warning %v, expected %v", example.value, got, example.warn)
+		}
+	}
+}
+
 type StringMapValidationTestCase struct {
 	TestName    string
 	Value       map[string]interface{}
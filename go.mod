@@ -9,5 +9,7 @@ require (
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/hashicorp/hil v0.0.0-20190212132231-97b3a9cdfa93 // indirect
 	github.com/hashicorp/terraform-plugin-sdk v1.0.0
+	github.com/zclconf/go-cty v1.1.0
+	github.com/zclconf/go-cty-yaml v1.0.1
 	golang.org/x/oauth2 v0.0.0-20190604053449-0f29369cfe45
 )